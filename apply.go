@@ -0,0 +1,167 @@
+package ssm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm/report"
+)
+
+// ApplyAction is what ApplyReport did, or would do, with a parameter
+type ApplyAction string
+
+const (
+	// ApplyCreate specifies that the parameter do not exist and is created
+	ApplyCreate ApplyAction = "create"
+	// ApplyUpdate specifies that the parameter differs from the live state and is updated
+	ApplyUpdate ApplyAction = "update"
+	// ApplyUnchanged specifies that the parameter is equal to the live state
+	// and is not written unless ApplyOptions.Force is set
+	ApplyUnchanged ApplyAction = "unchanged"
+)
+
+// ApplyOptions controls ApplyReport
+type ApplyOptions struct {
+	// DryRun only plans the apply, nothing is written.
+	DryRun bool
+	// Force writes all parameters, including those equal to the live state.
+	Force bool
+}
+
+// ApplyResult is the outcome of a single parameter in ApplyReport
+type ApplyResult struct {
+	// Name is the fully qualified name of the parameter
+	Name string
+	// Type is the parameter type
+	Type report.ParameterType
+	// Action is what was, or would be when dry run, done with the parameter
+	Action ApplyAction
+	// Fields is the fields that differs from the live state when ApplyUpdate
+	Fields []report.FieldDiff
+	// Error is set when the parameter failed to be written or is not supported.
+	// The parameter is unchanged remotely.
+	Error error
+}
+
+// ApplyResults is the results of ApplyReport sorted by name
+type ApplyResults []ApplyResult
+
+// ApplyReport creates or updates each parameter in the report without any go struct.
+// The parameters are written according to the type, value type, details (tier, pattern
+// and strkey), tags, description and key. Only parameters that are missing or differs
+// from the live state (as in DiffWithOpts with values) are written unless
+// ApplyOptions.Force is set. It returns one result per parameter sorted by name.
+//
+// This is typically used to edit reports generated in CI and to replay them in other
+// accounts by using a serializer configured for that account.
+func (s *Serializer) ApplyReport(rpt *report.Report, opts ApplyOptions) (ApplyResults, error) {
+	supported := &report.Report{Parameters: []report.Parameter{}}
+	results := ApplyResults{}
+
+	for _, prm := range rpt.Parameters {
+		if err := validateParameter(&prm); err != nil {
+			results = append(results, ApplyResult{Name: prm.Name, Type: prm.Type, Error: err})
+			continue
+		}
+
+		if prm.ValueType == "" {
+			prm.ValueType = defaultValueType(&prm)
+		}
+
+		supported.Parameters = append(supported.Parameters, prm)
+	}
+
+	live, err := s.DescribeReport(supported, false)
+	if err != nil {
+		return nil, err
+	}
+
+	planned, write := planApply(supported, live, opts.Force)
+
+	if !opts.DryRun && len(write) > 0 {
+		failed, err := s.PutParameters(write...)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range planned {
+			if field, ok := failed[planned[i].Name]; ok {
+				planned[i].Error = field.Error
+			}
+		}
+	}
+
+	results = append(results, planned...)
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	return results, nil
+}
+
+// planApply compares the local report with the live state and returns the result of
+// each parameter and the parameters to write.
+func planApply(local *report.Report, live *report.Report, force bool) (ApplyResults, []report.Parameter) {
+	diffs := map[string]report.ParameterDiff{}
+	for _, pd := range report.NewDiff(local, live, true).Parameters {
+		diffs[string(pd.Type)+pd.Name] = pd
+	}
+
+	results := make(ApplyResults, 0, len(local.Parameters))
+	write := []report.Parameter{}
+
+	for _, prm := range local.Parameters {
+		pd := diffs[string(prm.Type)+prm.Name]
+		result := ApplyResult{Name: prm.Name, Type: prm.Type, Action: ApplyUnchanged}
+
+		switch pd.Status {
+		case report.DiffMissing:
+			result.Action = ApplyCreate
+		case report.DiffChanged:
+			result.Action, result.Fields = ApplyUpdate, pd.Fields
+		}
+
+		if result.Action != ApplyUnchanged || force {
+			write = append(write, prm)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, write
+}
+
+// defaultValueType is the value type written when not set on the parameter
+func defaultValueType(prm *report.Parameter) string {
+	if prm.Type == report.SecretsManager || prm.KeyID != "" {
+		return string(types.ParameterTypeSecureString)
+	}
+
+	return string(types.ParameterTypeString)
+}
+
+// String renders the results, one line per parameter, prefixed with + when created,
+// ~ when updated, = when unchanged and ! when failed.
+func (results ApplyResults) String() string {
+	var sb strings.Builder
+
+	for _, r := range results {
+		switch {
+		case r.Error != nil:
+			sb.WriteString(fmt.Sprintf("! %s (%s) %v\n", r.Name, r.Type, r.Error))
+		case r.Action == ApplyCreate:
+			sb.WriteString(fmt.Sprintf("+ %s (%s)\n", r.Name, r.Type))
+		case r.Action == ApplyUpdate:
+			fields := make([]string, 0, len(r.Fields))
+			for _, f := range r.Fields {
+				fields = append(fields, f.Field)
+			}
+
+			sb.WriteString(fmt.Sprintf("~ %s (%s) %s\n", r.Name, r.Type, strings.Join(fields, ", ")))
+		default:
+			sb.WriteString(fmt.Sprintf("= %s (%s)\n", r.Name, r.Type))
+		}
+	}
+
+	return sb.String()
+}
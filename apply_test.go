@@ -0,0 +1,115 @@
+package ssm
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func applyReport() *report.Report {
+	return &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/test-service/created", Value: "a", ValueType: "String"},
+		{Type: report.ParameterStore, Name: "/prod/test-service/updated", Value: "new", ValueType: "String"},
+		{Type: report.ParameterStore, Name: "/prod/test-service/equal", Value: "same", ValueType: "String"},
+	}}
+}
+
+func applyLive() *report.Report {
+	return &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/test-service/created", Missing: true},
+		{Type: report.ParameterStore, Name: "/prod/test-service/updated", Value: "old", ValueType: "String"},
+		{Type: report.ParameterStore, Name: "/prod/test-service/equal", Value: "same", ValueType: "String"},
+	}}
+}
+
+func TestPlanApplyWritesOnlyMissingAndChanged(t *testing.T) {
+	results, write := planApply(applyReport(), applyLive(), false)
+
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, ApplyCreate, results[0].Action)
+	assert.Equal(t, ApplyUpdate, results[1].Action)
+	assert.Equal(t, "value", results[1].Fields[0].Field)
+	assert.Equal(t, ApplyUnchanged, results[2].Action)
+
+	assert.Equal(t, 2, len(write))
+	assert.Equal(t, "/prod/test-service/created", write[0].Name)
+	assert.Equal(t, "/prod/test-service/updated", write[1].Name)
+}
+
+func TestPlanApplyForceWritesAll(t *testing.T) {
+	_, write := planApply(applyReport(), applyLive(), true)
+	assert.Equal(t, 3, len(write))
+}
+
+func TestValidateParameterValueTypes(t *testing.T) {
+	assert.NoError(t, validateParameter(&report.Parameter{Type: report.ParameterStore, ValueType: "StringList"}))
+	assert.NoError(t, validateParameter(&report.Parameter{Type: report.SecretsManager}))
+	assert.Error(t, validateParameter(&report.Parameter{Type: report.ParameterStore, ValueType: "Binary"}))
+	assert.Error(t, validateParameter(&report.Parameter{Type: report.SecretsManager, ValueType: "String"}))
+	assert.Error(t, validateParameter(&report.Parameter{Type: "app-config"}))
+}
+
+func TestApplyReportFailsOnRedactedValue(t *testing.T) {
+	s := NewSsmSerializerFromConfig("prod", "test-service", aws.Config{Region: "eu-west-1"})
+	rpt := &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/test-service/key", Value: report.Redacted, ValueType: "SecureString"},
+		{Type: report.SecretsManager, Name: "/prod/test-service/db", Value: report.Redacted},
+	}}
+
+	results, err := s.ApplyReport(rpt, ApplyOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Error(t, results[0].Error)
+	assert.Error(t, results[1].Error)
+
+	failed, err := s.PutParameters(rpt.Parameters...)
+	assert.NoError(t, err)
+	assert.Contains(t, failed, "/prod/test-service/key")
+	assert.Contains(t, failed, "/prod/test-service/db")
+}
+
+type detailsTest struct {
+	Name string `pms:"name, description=The name, pattern=^[a-z]+$, keyid=alias/my-key"`
+}
+
+func TestMarshalDoNotWriteDetails(t *testing.T) {
+	stub := newParameterStub(map[string]string{})
+	defer stub.srv.Close()
+
+	s := NewSsmSerializerFromConfig("prod", "test-service", stub.config())
+
+	assert.Empty(t, s.MarshalWithOpts(&detailsTest{Name: "kalle"}, NoFilter, OnlyPms))
+	assert.Equal(t, 1, len(stub.puts))
+	assert.Equal(t, "/prod/test-service/name", stub.puts[0]["Name"])
+	assert.NotContains(t, stub.puts[0], "Description")
+	assert.NotContains(t, stub.puts[0], "AllowedPattern")
+	assert.NotContains(t, stub.puts[0], "KeyId")
+}
+
+func TestPutParametersWritesDetails(t *testing.T) {
+	stub := newParameterStub(map[string]string{})
+	defer stub.srv.Close()
+
+	s := NewSsmSerializerFromConfig("prod", "test-service", stub.config())
+
+	failed, err := s.PutParameters(report.Parameter{Type: report.ParameterStore, Name: "/prod/test-service/name",
+		Value: "kalle", ValueType: "SecureString", Description: "The name", KeyID: "alias/my-key",
+		Details: report.PmsParameterDetails{Pattern: "^[a-z]+$"}})
+
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.Equal(t, 1, len(stub.puts))
+	assert.Equal(t, "The name", stub.puts[0]["Description"])
+	assert.Equal(t, "^[a-z]+$", stub.puts[0]["AllowedPattern"])
+	assert.Equal(t, "alias/my-key", stub.puts[0]["KeyId"])
+}
+
+func TestApplyResultsString(t *testing.T) {
+	results, _ := planApply(applyReport(), applyLive(), false)
+
+	assert.Equal(t, "+ /prod/test-service/created (parameter-store)\n"+
+		"~ /prod/test-service/updated (parameter-store) value\n"+
+		"= /prod/test-service/equal (parameter-store)\n", results.String())
+}
@@ -0,0 +1,197 @@
+// Package cdkgo renders a Go AWS CDK (v2) construct out of a report.Report using
+// text/template. Parameters are rendered as awsssm.NewCfnParameter and secrets as
+// awssecretsmanager.NewCfnSecret. All templates may be replaced, see Templates.
+package cdkgo
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/mariotoffia/ssm/cloudformation"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/pkg/errors"
+)
+
+// DefaultClassName is the construct name when not set in Options
+const DefaultClassName = "SsmParamsConstruct"
+
+// Options controls how the construct is rendered
+type Options struct {
+	// Package is the go package name of the rendered file. Default is main.
+	Package string
+	// ClassName is the construct type name. Default is DefaultClassName.
+	ClassName string
+	// Templates overrides the default templates
+	Templates Templates
+}
+
+// File is the data the class template is executed with
+type File struct {
+	Package   string
+	ClassName string
+	// Parameters is the rendered parameter store parameters
+	Parameters []string
+	// Secrets is the rendered secrets
+	Secrets []string
+	// SecretTags is true when any secret has tags, i.e. the default secret templates
+	// refers to awscdk.CfnTag.
+	SecretTags bool
+}
+
+// Item is the data a parameter or secret template is executed with
+type Item struct {
+	// Index is the zero based index of the parameter, or secret, in report order
+	Index int
+	// ID is the construct id derived from the full name, e.g. SecretProdSvcDb.
+	ID string
+	// ValueType is the parameter value type, String if not set
+	ValueType string
+	// Parameter is the report parameter
+	Parameter *report.Parameter
+	// Pms is the parameter store details (empty for secrets)
+	Pms report.PmsParameterDetails
+	// Asm is the secrets manager details (empty for parameters)
+	Asm report.AsmParameterDetails
+	// Template is the secret string template when secrets manager generates the strkey value
+	Template string
+}
+
+// Tag is a single tag, the tags template function returns those sorted by key
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Construct is the rendered construct
+type Construct struct {
+	// Source is the gofmt formatted go source
+	Source []byte
+	// Skipped is the report parameters that could not be rendered, keyed by full
+	// name, with the reason as value.
+	Skipped map[string]string
+}
+
+// Render renders a go file with a single construct that creates all parameters and
+// secrets in the report. The construct ids are derived from the full names and are
+// hence stable.
+//
+// Secrets with a strkey use GenerateSecretString where the report value (without the
+// strkey) is the template. Other secrets, as in the TypeScript generator, get the report
+// value as secret string. Parameter store parameters with a SecureString value type is
+// not supported by CloudFormation and is skipped, as are parameter types not known by the
+// renderer.
+func Render(rpt *report.Report, opts Options) (*Construct, error) {
+	if opts.Package == "" {
+		opts.Package = "main"
+	}
+	if opts.ClassName == "" {
+		opts.ClassName = DefaultClassName
+	}
+
+	tmpls := opts.Templates.withDefaults()
+	root := template.New("cdkgo").Funcs(template.FuncMap{"quote": strconv.Quote, "tags": sortedTags})
+
+	if _, err := root.Parse(secretProps); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse shared templates")
+	}
+
+	var (
+		pmsTmpl, asmTmpl, asmgkTmpl, clzTmpl *template.Template
+		err                                  error
+	)
+
+	for _, t := range []struct {
+		name string
+		text string
+		tmpl **template.Template
+	}{
+		{"tmplpms", tmpls.Parameter, &pmsTmpl},
+		{"tmplasm", tmpls.Secret, &asmTmpl},
+		{"tmplasmgk", tmpls.GenerateSecret, &asmgkTmpl},
+		{"tmplclz", tmpls.Class, &clzTmpl},
+	} {
+		if *t.tmpl, err = root.New(t.name).Parse(t.text); err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse template %s", t.name)
+		}
+	}
+
+	file := File{Package: opts.Package, ClassName: opts.ClassName}
+	c := &Construct{Skipped: map[string]string{}}
+	ids := map[string]string{}
+
+	for i := range rpt.Parameters {
+		prm := &rpt.Parameters[i]
+		item := Item{Parameter: prm, ValueType: prm.ValueType}
+
+		var tmpl *template.Template
+
+		switch prm.Type {
+		case report.ParameterStore:
+			if prm.ValueType == "SecureString" {
+				c.Skipped[prm.Name] = "SecureString is not supported by AWS::SSM::Parameter"
+				continue
+			}
+
+			if item.ValueType == "" {
+				item.ValueType = "String"
+			}
+
+			item.Index = len(file.Parameters)
+			item.ID = cloudformation.LogicalID("Parameter", prm.Name, ids)
+			item.Pms, _ = prm.PmsDetails()
+			tmpl = pmsTmpl
+		case report.SecretsManager:
+			item.Index = len(file.Secrets)
+			item.ID = cloudformation.LogicalID("Secret", prm.Name, ids)
+			item.Asm, _ = prm.AsmDetails()
+			file.SecretTags = file.SecretTags || len(prm.Tags) > 0
+			tmpl = asmTmpl
+
+			if item.Asm.StringKey != "" {
+				item.Template = prm.SecretStringTemplate(item.Asm.StringKey)
+				tmpl = asmgkTmpl
+			}
+		default:
+			c.Skipped[prm.Name] = fmt.Sprintf("unsupported parameter type %s", prm.Type)
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, item); err != nil {
+			return nil, errors.Wrapf(err, "Failed to render %s", prm.Name)
+		}
+
+		if prm.Type == report.ParameterStore {
+			file.Parameters = append(file.Parameters, buf.String())
+		} else {
+			file.Secrets = append(file.Secrets, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := clzTmpl.Execute(&buf, file); err != nil {
+		return nil, errors.Wrap(err, "Failed to render class")
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Rendered source is not valid go:\n%s", buf.String())
+	}
+
+	c.Source = source
+	return c, nil
+}
+
+func sortedTags(tags map[string]string) []Tag {
+	list := make([]Tag, 0, len(tags))
+	for key, value := range tags {
+		list = append(list, Tag{Key: key, Value: value})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
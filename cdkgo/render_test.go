@@ -0,0 +1,177 @@
+package cdkgo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *report.Report {
+	return &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/test-service/db-host", Value: "localhost",
+			ValueType: "String", Description: "The host", Tags: map[string]string{"owner": "team"},
+			Details: report.PmsParameterDetails{Tier: "Advanced", Pattern: ".*"}},
+		{Type: report.ParameterStore, Name: "/prod/test-service/pwd", ValueType: "SecureString",
+			Details: report.PmsParameterDetails{Tier: "Standard"}},
+		{Type: report.SecretsManager, Name: "/prod/test-service/dbctx", ValueType: "SecureString",
+			Value: `{"user":"nisse","password":""}`, KeyID: "arn:aws:kms:eu-west-1:123:key/abc",
+			Tags: map[string]string{"b": "2", "a": "1"}, Details: report.AsmParameterDetails{StringKey: "password"}},
+		{Type: report.SecretsManager, Name: "/prod/test-service/apikey", ValueType: "SecureString",
+			Value: "very secret", Details: report.AsmParameterDetails{}},
+		{Type: "consul", Name: "/prod/test-service/flag"},
+	}}
+}
+
+func TestRenderDefaultTemplates(t *testing.T) {
+	c, err := Render(testReport(), Options{Package: "infra"})
+	assert.Equal(t, nil, err)
+
+	src := string(c.Source)
+	assert.Contains(t, src, "package infra")
+	assert.Contains(t, src, "type SsmParamsConstruct struct")
+	assert.Contains(t, src, `awsssm.NewCfnParameter(c, jsii.String("ParameterProdTestServiceDbHost")`)
+	assert.Contains(t, src, `AllowedPattern: jsii.String(".*")`)
+	assert.Contains(t, src, `Tier:           jsii.String("Advanced")`)
+	assert.Contains(t, src, `"owner": "team"`)
+	assert.Contains(t, src, `awssecretsmanager.NewCfnSecret(c, jsii.String("SecretProdTestServiceApikey")`)
+	assert.Contains(t, src, `SecretString: jsii.String("very secret")`)
+	assert.Contains(t, src, `KmsKeyId: jsii.String("arn:aws:kms:eu-west-1:123:key/abc")`)
+}
+
+func TestRenderGenerateSecret(t *testing.T) {
+	c, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	src := string(c.Source)
+	assert.Contains(t, src, `SecretStringTemplate: jsii.String("{\"user\":\"nisse\"}")`)
+	assert.Contains(t, src, `GenerateStringKey:    jsii.String("password")`)
+	assert.Regexp(t, `(?s)Key: jsii.String\("a"\).*Key: jsii.String\("b"\)`, src)
+}
+
+func TestRenderSkipsSecureStringAndUnknownTypes(t *testing.T) {
+	c, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 2, len(c.Skipped))
+	assert.Contains(t, c.Skipped, "/prod/test-service/pwd")
+	assert.Contains(t, c.Skipped, "/prod/test-service/flag")
+	assert.NotContains(t, string(c.Source), "/prod/test-service/pwd")
+}
+
+func TestRenderOnlyImportsUsedPackages(t *testing.T) {
+	rpt := &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/svc/name", Value: "nisse"},
+	}}
+
+	c, err := Render(rpt, Options{ClassName: "Params"})
+	assert.Equal(t, nil, err)
+
+	src := string(c.Source)
+	assert.Contains(t, src, "func NewParams(scope constructs.Construct, id string) *Params")
+	assert.Contains(t, src, `Type:  jsii.String("String")`)
+	assert.NotContains(t, src, "awssecretsmanager")
+	assert.NotContains(t, src, "awscdk/v2\"")
+}
+
+// fakeImporter returns empty packages named as the last, non version, path element
+type fakeImporter struct{}
+
+func (fakeImporter) Import(importPath string) (*types.Package, error) {
+	name := path.Base(importPath)
+	if strings.HasPrefix(name, "v") {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	pkg := types.NewPackage(importPath, strings.TrimSuffix(name, "-runtime-go"))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// unusedImports type checks the source and returns the imports that are not used. The
+// imported packages are empty hence all other errors are ignored.
+func unusedImports(t *testing.T, src []byte) []string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "construct.go", src, 0)
+	assert.Equal(t, nil, err)
+
+	unused := []string{}
+	conf := types.Config{Importer: fakeImporter{}, Error: func(err error) {
+		if msg := err.(types.Error).Msg; strings.Contains(msg, "imported") && strings.HasSuffix(msg, "not used") {
+			unused = append(unused, msg)
+		}
+	}}
+
+	conf.Check("infra", fset, []*ast.File{file}, nil)
+	return unused
+}
+
+func TestRenderUntaggedSecretCompiles(t *testing.T) {
+	rpt := &report.Report{Parameters: []report.Parameter{
+		{Type: report.SecretsManager, Name: "/prod/svc/apikey", Value: "very secret"},
+		{Type: report.SecretsManager, Name: "/prod/svc/db", Value: `{"user":"nisse"}`,
+			Details: report.AsmParameterDetails{StringKey: "password"}},
+	}}
+
+	c, err := Render(rpt, Options{})
+	assert.Equal(t, nil, err)
+	assert.NotContains(t, string(c.Source), "awscdk/v2\"")
+	assert.Empty(t, unusedImports(t, c.Source))
+
+	c, err = Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+	assert.Contains(t, string(c.Source), "awscdk/v2\"")
+	assert.Empty(t, unusedImports(t, c.Source))
+}
+
+func TestRenderIsStable(t *testing.T) {
+	first, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	second, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, string(first.Source), string(second.Source))
+}
+
+func TestRenderCustomTemplates(t *testing.T) {
+	c, err := Render(testReport(), Options{Templates: Templates{
+		Secret: `	// secret {{.Index}} {{.Parameter.Name}}`,
+	}})
+	assert.Equal(t, nil, err)
+
+	src := string(c.Source)
+	assert.Contains(t, src, "// secret 1 /prod/test-service/apikey")
+	assert.Contains(t, src, "GenerateSecretString")
+}
+
+func TestRenderInvalidTemplateFails(t *testing.T) {
+	_, err := Render(testReport(), Options{Templates: Templates{Parameter: "{{.Nope"}})
+	assert.Error(t, err)
+}
+
+func TestTemplatesFromFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cdkgo")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	pms := filepath.Join(dir, "pms.txt")
+	assert.Equal(t, nil, ioutil.WriteFile(pms, []byte("// {{.ID}}"), 0600))
+
+	tmpls, err := TemplatesFromFiles("", pms, "", "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "// {{.ID}}", tmpls.Parameter)
+	assert.Equal(t, "", tmpls.Class)
+
+	_, err = TemplatesFromFiles(filepath.Join(dir, "missing.txt"), "", "", "")
+	assert.Error(t, err)
+}
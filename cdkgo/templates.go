@@ -0,0 +1,181 @@
+package cdkgo
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// Templates is the text/template sources used when rendering. Empty fields use the
+// default templates. These mirror the tmplclz, tmplpms, tmplasm and tmplasmgk options
+// of the TypeScript generator in the cdk folder.
+type Templates struct {
+	// Class is the file template. It is executed with a File and the rendered
+	// Parameters and Secrets are available as strings.
+	Class string
+	// Parameter renders a single parameter store parameter, executed with a Item
+	Parameter string
+	// Secret renders a single secret that is set from the report value, executed with a Item
+	Secret string
+	// GenerateSecret renders a single secret that has a strkey and hence secrets manager
+	// generates the value into the template. It is executed with a Item.
+	GenerateSecret string
+}
+
+// TemplatesFromFiles reads the templates from the files. Any empty filename uses the
+// default template.
+func TemplatesFromFiles(tmplclz, tmplpms, tmplasm, tmplasmgk string) (Templates, error) {
+	tmpls := Templates{}
+
+	files := []struct {
+		filename string
+		text     *string
+	}{
+		{tmplclz, &tmpls.Class},
+		{tmplpms, &tmpls.Parameter},
+		{tmplasm, &tmpls.Secret},
+		{tmplasmgk, &tmpls.GenerateSecret},
+	}
+
+	for _, f := range files {
+		if f.filename == "" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(f.filename)
+		if err != nil {
+			return Templates{}, errors.Wrapf(err, "Failed to read template %s", f.filename)
+		}
+
+		*f.text = string(data)
+	}
+
+	return tmpls, nil
+}
+
+func (t Templates) withDefaults() Templates {
+	if t.Class == "" {
+		t.Class = DefaultClassTemplate
+	}
+	if t.Parameter == "" {
+		t.Parameter = DefaultParameterTemplate
+	}
+	if t.Secret == "" {
+		t.Secret = DefaultSecretTemplate
+	}
+	if t.GenerateSecret == "" {
+		t.GenerateSecret = DefaultGenerateSecretTemplate
+	}
+
+	return t
+}
+
+// DefaultClassTemplate is the default file template. It only imports the packages in
+// use since go do not allow unused imports.
+const DefaultClassTemplate = `// Code generated by ssm cdkgo. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .SecretTags}}
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+{{- end}}
+{{- if .Secrets}}
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
+{{- end}}
+{{- if .Parameters}}
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsssm"
+{{- end}}
+	"github.com/aws/constructs-go/constructs/v10"
+{{- if or .Secrets .Parameters}}
+	"github.com/aws/jsii-runtime-go"
+{{- end}}
+)
+
+// {{.ClassName}} provisions the parameters and secrets
+type {{.ClassName}} struct {
+	constructs.Construct
+}
+
+// New{{.ClassName}} creates the construct with all parameters and secrets
+func New{{.ClassName}}(scope constructs.Construct, id string) *{{.ClassName}} {
+	c := &{{.ClassName}}{Construct: constructs.NewConstruct(scope, &id)}
+
+	c.setupSecrets()
+	c.setupParameters()
+
+	return c
+}
+
+func (c *{{.ClassName}}) setupSecrets() {
+{{- range .Secrets}}
+{{.}}
+{{- end}}
+}
+
+func (c *{{.ClassName}}) setupParameters() {
+{{- range .Parameters}}
+{{.}}
+{{- end}}
+}
+`
+
+// DefaultParameterTemplate is the default awsssm.NewCfnParameter template
+const DefaultParameterTemplate = `	awsssm.NewCfnParameter(c, jsii.String({{quote .ID}}), &awsssm.CfnParameterProps{
+		Name:  jsii.String({{quote .Parameter.Name}}),
+		Type:  jsii.String({{quote .ValueType}}),
+		Value: jsii.String({{quote .Parameter.Value}}),
+{{- if .Parameter.Description}}
+		Description: jsii.String({{quote .Parameter.Description}}),
+{{- end}}
+{{- if .Pms.Pattern}}
+		AllowedPattern: jsii.String({{quote .Pms.Pattern}}),
+{{- end}}
+{{- if .Pms.Tier}}
+		Tier: jsii.String({{quote (print .Pms.Tier)}}),
+{{- end}}
+{{- if .Parameter.Tags}}
+		Tags: map[string]string{
+{{- range tags .Parameter.Tags}}
+			{{quote .Key}}: {{quote .Value}},
+{{- end}}
+		},
+{{- end}}
+	})`
+
+// DefaultSecretTemplate is the default awssecretsmanager.NewCfnSecret template where the
+// secret string is the report value.
+const DefaultSecretTemplate = `	awssecretsmanager.NewCfnSecret(c, jsii.String({{quote .ID}}), &awssecretsmanager.CfnSecretProps{
+		Name:         jsii.String({{quote .Parameter.Name}}),
+		SecretString: jsii.String({{quote .Parameter.Value}}),
+{{- template "secretprops" .}}
+	})`
+
+// DefaultGenerateSecretTemplate is the default awssecretsmanager.NewCfnSecret template
+// where secrets manager generates the strkey value into the report value template.
+const DefaultGenerateSecretTemplate = `	awssecretsmanager.NewCfnSecret(c, jsii.String({{quote .ID}}), &awssecretsmanager.CfnSecretProps{
+		Name: jsii.String({{quote .Parameter.Name}}),
+		GenerateSecretString: &awssecretsmanager.CfnSecret_GenerateSecretStringProperty{
+			SecretStringTemplate: jsii.String({{quote .Template}}),
+			GenerateStringKey:    jsii.String({{quote .Asm.StringKey}}),
+		},
+{{- template "secretprops" .}}
+	})`
+
+// secretProps is the shared properties of the secret templates. It is available to
+// user templates as well.
+const secretProps = `{{define "secretprops"}}
+{{- if .Parameter.Description}}
+		Description: jsii.String({{quote .Parameter.Description}}),
+{{- end}}
+{{- if .Parameter.KeyID}}
+		KmsKeyId: jsii.String({{quote .Parameter.KeyID}}),
+{{- end}}
+{{- if .Parameter.Tags}}
+		Tags: &[]*awscdk.CfnTag{
+{{- range tags .Parameter.Tags}}
+			{Key: jsii.String({{quote .Key}}), Value: jsii.String({{quote .Value}})},
+{{- end}}
+		},
+{{- end}}
+{{- end}}`
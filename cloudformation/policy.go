@@ -0,0 +1,54 @@
+package cloudformation
+
+import (
+	"strings"
+
+	"github.com/mariotoffia/ssm/iam"
+)
+
+// TypeManagedPolicy is the CloudFormation type of a IAM managed policy
+const TypeManagedPolicy = "AWS::IAM::ManagedPolicy"
+
+// AddManagedPolicy adds the policy document as a managed policy resource, attached
+// to the roles (if any). Resources containing pseudo parameters, e.g. when rendered
+// using iam.CloudFormationOptions, are substituted using Fn::Sub.
+func (t *Template) AddManagedPolicy(logicalID string, doc *iam.Document, roles ...string) {
+	statements := make([]map[string]interface{}, 0, len(doc.Statement))
+
+	for _, st := range doc.Statement {
+		resources := make([]interface{}, 0, len(st.Resource))
+		for _, resource := range st.Resource {
+			if strings.Contains(resource, "${") {
+				resources = append(resources, map[string]string{"Fn::Sub": resource})
+			} else {
+				resources = append(resources, resource)
+			}
+		}
+
+		statement := map[string]interface{}{
+			"Sid":      st.Sid,
+			"Effect":   st.Effect,
+			"Action":   st.Action,
+			"Resource": resources,
+		}
+
+		if len(st.Condition) > 0 {
+			statement["Condition"] = st.Condition
+		}
+
+		statements = append(statements, statement)
+	}
+
+	props := map[string]interface{}{
+		"PolicyDocument": map[string]interface{}{
+			"Version":   doc.Version,
+			"Statement": statements,
+		},
+	}
+
+	if len(roles) > 0 {
+		props["Roles"] = roles
+	}
+
+	t.Resources[logicalID] = Resource{Type: TypeManagedPolicy, Properties: props}
+}
@@ -0,0 +1,31 @@
+package cloudformation
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddManagedPolicySubstitutesPseudoParameters(t *testing.T) {
+	tmpl, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	doc := &iam.Document{Version: iam.PolicyVersion, Statement: []iam.Statement{{
+		Effect:   "Allow",
+		Action:   []string{"ssm:GetParameters"},
+		Resource: []string{"arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/prod/x", "*"},
+	}}}
+
+	tmpl.AddManagedPolicy("ReadPolicy", doc, "my-role")
+
+	res := tmpl.Resources["ReadPolicy"]
+	assert.Equal(t, TypeManagedPolicy, res.Type)
+	assert.Equal(t, []string{"my-role"}, res.Properties["Roles"])
+
+	statements := res.Properties["PolicyDocument"].(map[string]interface{})["Statement"].([]map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]string{"Fn::Sub": "arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/prod/x"},
+		"*",
+	}, statements[0]["Resource"])
+}
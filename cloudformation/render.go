@@ -0,0 +1,203 @@
+package cloudformation
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mariotoffia/ssm/report"
+)
+
+const (
+	// TypeParameter is the CloudFormation type of a parameter store parameter
+	TypeParameter = "AWS::SSM::Parameter"
+	// TypeSecret is the CloudFormation type of a secrets manager secret
+	TypeSecret = "AWS::SecretsManager::Secret"
+)
+
+// Options controls how the template is rendered
+type Options struct {
+	// Description is the template description
+	Description string
+	// ExportPrefix, when set, exports all outputs with the prefix followed by the logical id
+	ExportPrefix string
+}
+
+// Render renders a template with one resource, and output, per parameter in the report.
+// The logical ids are derived from the full names and are hence stable.
+//
+// Secrets with a strkey use GenerateSecretString where the report value (without the
+// strkey) is the template. Other secrets get their value from a NoEcho template parameter,
+// hence no secret value is written into the template. Parameter store parameters with
+// a SecureString value type is not supported by CloudFormation and is skipped, as are
+// parameter types not known by the renderer.
+func Render(rpt *report.Report, opts Options) (*Template, error) {
+	t := &Template{
+		AWSTemplateFormatVersion: FormatVersion,
+		Description:              opts.Description,
+		Parameters:               map[string]Parameter{},
+		Resources:                map[string]Resource{},
+		Outputs:                  map[string]Output{},
+		Skipped:                  map[string]string{},
+	}
+
+	ids := map[string]string{}
+
+	for i := range rpt.Parameters {
+		prm := &rpt.Parameters[i]
+
+		switch prm.Type {
+		case report.ParameterStore:
+			if prm.ValueType == "SecureString" {
+				t.Skipped[prm.Name] = "SecureString is not supported by AWS::SSM::Parameter"
+				continue
+			}
+
+			t.addParameter(LogicalID("Parameter", prm.Name, ids), prm)
+		case report.SecretsManager:
+			t.addSecret(LogicalID("Secret", prm.Name, ids), prm)
+		default:
+			t.Skipped[prm.Name] = fmt.Sprintf("unsupported parameter type %s", prm.Type)
+		}
+	}
+
+	for id := range t.Resources {
+		out := Output{Description: t.Resources[id].Properties["Name"].(string), Value: Ref(id)}
+		if opts.ExportPrefix != "" {
+			out.Export = &Export{Name: opts.ExportPrefix + id}
+		}
+
+		t.Outputs[id] = out
+	}
+
+	return t, nil
+}
+
+func (t *Template) addParameter(id string, prm *report.Parameter) {
+	props := map[string]interface{}{
+		"Name": prm.Name,
+		"Type": "String",
+	}
+
+	if prm.ValueType == "StringList" {
+		props["Type"] = "StringList"
+	}
+
+	if prm.Value != "" {
+		props["Value"] = prm.Value
+	} else {
+		// CloudFormation requires a value, let the deployer supply it
+		t.Parameters[id+"Value"] = Parameter{Type: "String", Description: prm.Name}
+		props["Value"] = Ref(id + "Value")
+	}
+
+	if prm.Description != "" {
+		props["Description"] = prm.Description
+	}
+
+	if len(prm.Tags) > 0 {
+		props["Tags"] = prm.Tags
+	}
+
+	if details, ok := prm.PmsDetails(); ok {
+		if details.Tier != "" {
+			props["Tier"] = string(details.Tier)
+		}
+
+		if details.Pattern != "" {
+			props["AllowedPattern"] = details.Pattern
+		}
+	}
+
+	if len(prm.Policies) > 0 {
+		props["Policies"] = "[" + strings.Join(prm.Policies, ",") + "]"
+	}
+
+	t.Resources[id] = Resource{Type: TypeParameter, Properties: props}
+}
+
+func (t *Template) addSecret(id string, prm *report.Parameter) {
+	props := map[string]interface{}{
+		"Name": prm.Name,
+	}
+
+	if details, ok := prm.AsmDetails(); ok && details.StringKey != "" {
+		props["GenerateSecretString"] = map[string]interface{}{
+			"SecretStringTemplate": prm.SecretStringTemplate(details.StringKey),
+			"GenerateStringKey":    details.StringKey,
+		}
+	} else {
+		t.Parameters[id+"Value"] = Parameter{Type: "String", Description: prm.Name, NoEcho: true}
+		props["SecretString"] = Ref(id + "Value")
+	}
+
+	if prm.Description != "" {
+		props["Description"] = prm.Description
+	}
+
+	if prm.KeyID != "" {
+		props["KmsKeyId"] = prm.KeyID
+	}
+
+	if len(prm.Tags) > 0 {
+		props["Tags"] = tagList(prm.Tags)
+	}
+
+	t.Resources[id] = Resource{Type: TypeSecret, Properties: props}
+}
+
+// tagList converts the tags into a CloudFormation Key / Value list sorted by key
+func tagList(tags map[string]string) []map[string]string {
+	list := []map[string]string{}
+	for _, key := range sortedKeys(tags) {
+		list = append(list, map[string]string{"Key": key, "Value": tags[key]})
+	}
+
+	return list
+}
+
+// LogicalID derives a CloudFormation logical id from the full name, e.g. Parameter and
+// /prod/test-service/db-host becomes ParameterProdTestServiceDbHost. If two names
+// renders the same id, a hash of the name is appended. The ids map holds the names
+// keyed by rendered id.
+func LogicalID(kind string, name string, ids map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString(kind)
+
+	upper := true
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		sb.WriteRune(r)
+	}
+
+	id := sb.String()
+	if existing, ok := ids[id]; ok && existing != name {
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		id = fmt.Sprintf("%s%08X", id, h.Sum32())
+	}
+
+	ids[id] = name
+	return id
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package cloudformation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *report.Report {
+	return &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/test-service/db-host", Value: "localhost",
+			ValueType: "String", Description: "The host", Tags: map[string]string{"owner": "team"},
+			Details: report.PmsParameterDetails{Tier: "Advanced", Pattern: ".*"}},
+		{Type: report.ParameterStore, Name: "/prod/test-service/pwd", ValueType: "SecureString",
+			Details: report.PmsParameterDetails{Tier: "Standard"}},
+		{Type: report.SecretsManager, Name: "/prod/test-service/dbctx", ValueType: "SecureString",
+			Value: `{"user":"nisse","password":""}`, KeyID: "arn:aws:kms:eu-west-1:123:key/abc",
+			Tags: map[string]string{"b": "2", "a": "1"}, Details: report.AsmParameterDetails{StringKey: "password"}},
+		{Type: report.SecretsManager, Name: "/prod/test-service/apikey", ValueType: "SecureString",
+			Value: "very secret", Details: report.AsmParameterDetails{}},
+		{Type: "consul", Name: "/prod/test-service/flag"},
+	}}
+}
+
+func TestRenderParameter(t *testing.T) {
+	tmpl, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	res := tmpl.Resources["ParameterProdTestServiceDbHost"]
+	assert.Equal(t, TypeParameter, res.Type)
+	assert.Equal(t, "/prod/test-service/db-host", res.Properties["Name"])
+	assert.Equal(t, "localhost", res.Properties["Value"])
+	assert.Equal(t, "Advanced", res.Properties["Tier"])
+	assert.Equal(t, ".*", res.Properties["AllowedPattern"])
+	assert.Equal(t, "The host", res.Properties["Description"])
+	assert.Equal(t, map[string]string{"owner": "team"}, res.Properties["Tags"])
+}
+
+func TestRenderSkipsSecureStringAndUnknownTypes(t *testing.T) {
+	tmpl, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 3, len(tmpl.Resources))
+	assert.Contains(t, tmpl.Skipped, "/prod/test-service/pwd")
+	assert.Contains(t, tmpl.Skipped, "/prod/test-service/flag")
+}
+
+func TestRenderSecretWithStringKeyGenerates(t *testing.T) {
+	tmpl, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	res := tmpl.Resources["SecretProdTestServiceDbctx"]
+	assert.Equal(t, TypeSecret, res.Type)
+	assert.Equal(t, map[string]interface{}{
+		"SecretStringTemplate": `{"user":"nisse"}`,
+		"GenerateStringKey":    "password",
+	}, res.Properties["GenerateSecretString"])
+	assert.Equal(t, "arn:aws:kms:eu-west-1:123:key/abc", res.Properties["KmsKeyId"])
+	assert.Equal(t, []map[string]string{{"Key": "a", "Value": "1"}, {"Key": "b", "Value": "2"}},
+		res.Properties["Tags"])
+}
+
+func TestRenderSecretValueIsNoEchoParameter(t *testing.T) {
+	tmpl, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	res := tmpl.Resources["SecretProdTestServiceApikey"]
+	assert.Equal(t, Ref("SecretProdTestServiceApikeyValue"), res.Properties["SecretString"])
+	assert.True(t, tmpl.Parameters["SecretProdTestServiceApikeyValue"].NoEcho)
+
+	data, err := tmpl.JSON()
+	assert.Equal(t, nil, err)
+	assert.NotContains(t, string(data), "very secret")
+}
+
+func TestRenderOutputsWithExport(t *testing.T) {
+	tmpl, err := Render(testReport(), Options{ExportPrefix: "Stack-"})
+	assert.Equal(t, nil, err)
+
+	out := tmpl.Outputs["ParameterProdTestServiceDbHost"]
+	assert.Equal(t, Ref("ParameterProdTestServiceDbHost"), out.Value)
+	assert.Equal(t, "Stack-ParameterProdTestServiceDbHost", out.Export.Name)
+}
+
+func TestRenderFromJSONReport(t *testing.T) {
+	data, err := json.Marshal(testReport())
+	assert.Equal(t, nil, err)
+
+	var rpt report.Report
+	assert.Equal(t, nil, json.Unmarshal(data, &rpt))
+
+	tmpl, err := Render(&rpt, Options{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Advanced", tmpl.Resources["ParameterProdTestServiceDbHost"].Properties["Tier"])
+	assert.NotNil(t, tmpl.Resources["SecretProdTestServiceDbctx"].Properties["GenerateSecretString"])
+}
+
+func TestRenderYAMLIsDeterministic(t *testing.T) {
+	tmpl, err := Render(testReport(), Options{Description: "ssm"})
+	assert.Equal(t, nil, err)
+
+	first, err := tmpl.YAML()
+	assert.Equal(t, nil, err)
+
+	tmpl, _ = Render(testReport(), Options{Description: "ssm"})
+	second, _ := tmpl.YAML()
+
+	assert.Equal(t, string(first), string(second))
+	assert.Contains(t, string(first), "AWSTemplateFormatVersion: \"2010-09-09\"")
+	assert.Contains(t, string(first), "Type: AWS::SSM::Parameter")
+}
+
+func TestLogicalIDCollisionAppendsHash(t *testing.T) {
+	ids := map[string]string{}
+
+	assert.Equal(t, "ParameterProdSvcAB", LogicalID("Parameter", "/prod/svc/a-b", ids))
+	assert.Equal(t, "ParameterProdSvcAB", LogicalID("Parameter", "/prod/svc/a-b", ids))
+
+	other := LogicalID("Parameter", "/prod/svc/a_b", ids)
+	assert.NotEqual(t, "ParameterProdSvcAB", other)
+	assert.Equal(t, other, LogicalID("Parameter", "/prod/svc/a_b", ids))
+}
@@ -0,0 +1,76 @@
+// Package cloudformation renders a CloudFormation template, in JSON or YAML, out of
+// a report.Report. Parameters are rendered as AWS::SSM::Parameter and secrets as
+// AWS::SecretsManager::Secret resources.
+package cloudformation
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is the CloudFormation template format version
+const FormatVersion = "2010-09-09"
+
+// Template is a CloudFormation template
+type Template struct {
+	AWSTemplateFormatVersion string               `json:"AWSTemplateFormatVersion" yaml:"AWSTemplateFormatVersion"`
+	Description              string               `json:"Description,omitempty" yaml:"Description,omitempty"`
+	Parameters               map[string]Parameter `json:"Parameters,omitempty" yaml:"Parameters,omitempty"`
+	Resources                map[string]Resource  `json:"Resources" yaml:"Resources"`
+	Outputs                  map[string]Output    `json:"Outputs,omitempty" yaml:"Outputs,omitempty"`
+	// Skipped is the report parameters that could not be rendered, keyed by full
+	// name, with the reason as value. It is not part of the template.
+	Skipped map[string]string `json:"-" yaml:"-"`
+}
+
+// Parameter is a template input parameter
+type Parameter struct {
+	Type        string `json:"Type" yaml:"Type"`
+	Description string `json:"Description,omitempty" yaml:"Description,omitempty"`
+	NoEcho      bool   `json:"NoEcho,omitempty" yaml:"NoEcho,omitempty"`
+}
+
+// Resource is a template resource
+type Resource struct {
+	Type       string                 `json:"Type" yaml:"Type"`
+	Properties map[string]interface{} `json:"Properties" yaml:"Properties"`
+}
+
+// Output is a template output
+type Output struct {
+	Description string      `json:"Description,omitempty" yaml:"Description,omitempty"`
+	Value       interface{} `json:"Value" yaml:"Value"`
+	Export      *Export     `json:"Export,omitempty" yaml:"Export,omitempty"`
+}
+
+// Export exports an output value to be imported by other stacks
+type Export struct {
+	Name string `json:"Name" yaml:"Name"`
+}
+
+// Ref creates a Ref intrinsic function to the logical id
+func Ref(logicalID string) map[string]string {
+	return map[string]string{"Ref": logicalID}
+}
+
+// JSON renders the template as indented JSON
+func (t *Template) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// YAML renders the template as YAML indented with two spaces
+func (t *Template) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(t); err != nil {
+		return nil, err
+	}
+
+	err := encoder.Close()
+	return buf.Bytes(), err
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+// runGet reads the names and writes the live values
+func runGet(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("get", opts)
+	storeFlag(fs, opts)
+	redact := fs.Bool("redact", false, "replace secure values with "+report.Redacted)
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return errors.New("at least one name is required")
+	}
+
+	params, err := opts.parameters(names)
+	if err != nil {
+		return err
+	}
+
+	rpt, err := opts.serializer().DescribeReport(&report.Report{Parameters: params}, *redact)
+	if err != nil {
+		return err
+	}
+
+	return writeReport(stdout, rpt, opts.output, opts.base())
+}
+
+// runPut writes a single value
+func runPut(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("put", opts)
+	storeFlag(fs, opts)
+	secure := fs.Bool("secure", false, "encrypt the parameter (secrets are always encrypted)")
+	keyid := fs.String("keyid", "", "KMS key to encrypt with, default is the account key")
+	description := fs.String("description", "", "description")
+	tier := fs.String("tier", "", "parameter store tier: Standard, Advanced or Intelligent-Tiering")
+	pattern := fs.String("pattern", "", "parameter store allowed pattern")
+	tags := fs.String("tags", "", "tags on the form key=value,key=value")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 2 {
+		return errors.New("a name and a value is required")
+	}
+
+	params, err := opts.parameters(positional[:1])
+	if err != nil {
+		return err
+	}
+
+	prm := params[0]
+	prm.Value = positional[1]
+	prm.Description = *description
+	prm.KeyID = *keyid
+
+	if prm.Tags, err = parseTags(*tags); err != nil {
+		return err
+	}
+
+	switch {
+	case prm.Type == report.SecretsManager, *secure, *keyid != "":
+		prm.ValueType = "SecureString"
+	default:
+		prm.ValueType = "String"
+	}
+
+	if prm.Type == report.ParameterStore {
+		t, err := tierOf(*tier)
+		if err != nil {
+			return err
+		}
+
+		prm.Details = report.PmsParameterDetails{Tier: t, Pattern: *pattern}
+	}
+
+	failed, err := opts.serializer().PutParameters(prm)
+	if err != nil {
+		return err
+	}
+
+	return writeInvalid(stdout, failed, opts.output)
+}
+
+// runReport writes the report of a file or plugin, optionally with the live state
+func runReport(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("report", opts)
+	sourceFlags(fs, opts)
+	fs.Lookup("output").DefValue = formatJSON
+	fs.Set("output", formatJSON)
+	values := fs.Bool("values", false, "include the values of the plugin struct")
+	remote := fs.Bool("remote", false, "fill in the live state of each parameter")
+	redact := fs.Bool("redact", true, "replace secure values with "+report.Redacted+" when -remote")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if !opts.hasSource() {
+		return errors.New("either -file or -plugin is required")
+	}
+
+	rpt, err := opts.loadReport(*values)
+	if err != nil {
+		return err
+	}
+
+	if *remote {
+		if rpt, err = opts.serializer().DescribeReport(rpt, *redact); err != nil {
+			return err
+		}
+	}
+
+	return writeReport(stdout, rpt, opts.output, opts.base())
+}
+
+// runApply creates or updates the parameters in the report file that differs from the
+// live state
+func runApply(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("apply", opts)
+	fs.StringVar(&opts.file, "file", "", "report JSON file")
+	dryRun := fs.Bool("dry-run", false, "only print what would be written")
+	force := fs.Bool("force", false, "write all parameters, including those equal to the live state")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if opts.file == "" {
+		return errors.New("-file is required")
+	}
+
+	rpt, err := opts.readReport()
+	if err != nil {
+		return err
+	}
+
+	results, err := opts.serializer().ApplyReport(rpt, ssm.ApplyOptions{DryRun: *dryRun, Force: *force})
+	if err != nil {
+		return err
+	}
+
+	if opts.output == formatJSON {
+		err = writeJSON(stdout, applyResults(results))
+	} else {
+		_, err = fmt.Fprint(stdout, results.String())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d failed to be written", failed, len(results))
+	}
+
+	return nil
+}
+
+// applyResult is the JSON output of a single ssm.ApplyResult
+type applyResult struct {
+	Name   string               `json:"fqname"`
+	Type   report.ParameterType `json:"type"`
+	Action ssm.ApplyAction      `json:"action"`
+	Fields []report.FieldDiff   `json:"fields,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+func applyResults(results ssm.ApplyResults) []applyResult {
+	out := make([]applyResult, 0, len(results))
+	for _, r := range results {
+		ar := applyResult{Name: r.Name, Type: r.Type, Action: r.Action, Fields: r.Fields}
+		if r.Error != nil {
+			ar.Error = r.Error.Error()
+		}
+
+		out = append(out, ar)
+	}
+
+	return out
+}
+
+// runDelete deletes the names or all parameters in the report file. It is a dry run
+// unless -commit is set.
+func runDelete(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("delete", opts)
+	storeFlag(fs, opts)
+	fs.StringVar(&opts.file, "file", "", "report JSON file with the parameters to delete")
+	commit := fs.Bool("commit", false, "delete, otherwise only print what would be deleted")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	params, err := opts.parameters(names)
+	if err != nil {
+		return err
+	}
+
+	if opts.file != "" {
+		rpt, err := opts.readReport()
+		if err != nil {
+			return err
+		}
+
+		params = append(params, rpt.Parameters...)
+	}
+
+	if len(params) == 0 {
+		return errors.New("at least one name, or -file, is required")
+	}
+
+	if !*commit {
+		deleted := make([]string, 0, len(params))
+		for _, prm := range params {
+			deleted = append(deleted, prm.Name)
+		}
+
+		return writeNames(stdout, deleted, opts.output)
+	}
+
+	failed, err := opts.serializer().DeleteParameters(params...)
+	if err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		if err := writeFailed(stdout, failed, opts.output); err != nil {
+			return err
+		}
+
+		return errors.Errorf("%d of %d failed to be deleted", len(failed), len(params))
+	}
+
+	return nil
+}
+
+// runPrune deletes the orphans of the plugin struct. It is a dry run unless -commit is set.
+func runPrune(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("prune", opts)
+	fs.StringVar(&opts.plugin, "plugin", "", "go plugin that exports the tagged struct")
+	fs.StringVar(&opts.symbol, "symbol", "", "name of the exported struct variable in the plugin")
+	allow := fs.String("allow", "", "comma separated names that never are pruned, a trailing * is a prefix")
+	commit := fs.Bool("commit", false, "delete, otherwise only print what would be deleted")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	v, err := opts.lookupStruct()
+	if err != nil {
+		return err
+	}
+
+	result, err := opts.serializer().Prune(ssm.PruneOptions{Commit: *commit, Allow: parseList(*allow)}, v)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(result.Deleted))
+	for _, orphan := range result.Deleted {
+		names = append(names, orphan.Name)
+	}
+
+	if err := writeNames(stdout, names, opts.output); err != nil {
+		return err
+	}
+
+	if len(result.Failed) > 0 {
+		if err := writeFailed(stdout, result.Failed, opts.output); err != nil {
+			return err
+		}
+
+		return errors.Errorf("%d orphans failed to be deleted", len(result.Failed))
+	}
+
+	return nil
+}
+
+// runDiff compares the live state of the parameters in one environment with another
+func runDiff(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("diff", opts)
+	sourceFlags(fs, opts)
+	from := fs.String("from", "", "environment to compare from")
+	to := fs.String("to", "", "environment to compare with")
+	values := fs.Bool("values", false, "compare the values as well")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return errors.New("both -from and -to are required")
+	}
+
+	if !opts.hasSource() {
+		return errors.New("either -file or -plugin is required")
+	}
+
+	opts.env = *from
+
+	rpt, err := opts.loadReport(false)
+	if err != nil {
+		return err
+	}
+
+	diff, err := diffEnvironments(opts.serializer(), rpt, *from, *to, *values)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == formatJSON {
+		return writeJSON(stdout, diff)
+	}
+
+	_, err = fmt.Fprint(stdout, diff.String())
+	return err
+}
+
+// diffEnvironments reads the live state of the parameters in the from environment and
+// the same names in the to environment. The diff is keyed by the from names where local
+// is the from and remote is the to environment.
+func diffEnvironments(s *ssm.Serializer, rpt *report.Report, from string, to string,
+	values bool) (*report.Diff, error) {
+
+	renamed := &report.Report{Parameters: make([]report.Parameter, len(rpt.Parameters))}
+	for i, prm := range rpt.Parameters {
+		if prefix := "/" + from + "/"; strings.HasPrefix(prm.Name, prefix) {
+			prm.Name = "/" + to + "/" + strings.TrimPrefix(prm.Name, prefix)
+		}
+
+		renamed.Parameters[i] = prm
+	}
+
+	fromState, err := s.DescribeReport(rpt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	toState, err := s.DescribeReport(renamed, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range toState.Parameters {
+		toState.Parameters[i].Name = rpt.Parameters[i].Name
+	}
+
+	return report.NewDiff(fromState, toState, values), nil
+}
+
+// writeInvalid writes the fields that failed to be written and returns an error if any
+func writeInvalid(w io.Writer, invalid map[string]support.FullNameField, format string) error {
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	failed := map[string]error{}
+	for name, field := range invalid {
+		failed[name] = field.Error
+	}
+
+	if err := writeFailed(w, failed, format); err != nil {
+		return err
+	}
+
+	return errors.Errorf("%d failed to be written", len(failed))
+}
+
+// tierOf returns the parameter store tier, empty uses the serializer tier
+func tierOf(tier string) (types.ParameterTier, error) {
+	if tier == "" {
+		return "", nil
+	}
+
+	for _, t := range types.ParameterTierStandard.Values() {
+		if strings.EqualFold(string(t), tier) {
+			return t, nil
+		}
+	}
+
+	return "", errors.Errorf("unknown tier %s, use Standard, Advanced or Intelligent-Tiering", tier)
+}
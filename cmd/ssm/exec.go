@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mariotoffia/ssm"
+	"github.com/pkg/errors"
+)
+
+// runExec reads the parameters and secrets and executes the command with those as
+// environment variables. The values are never printed.
+func runExec(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("exec", opts)
+	fs.StringVar(&opts.file, "file", "", "report JSON file with the parameters to read")
+	paths := fs.String("path", "", "comma separated prefixes to read all parameters and secrets under")
+	trim := fs.String("trim", "", "prefix to remove from the names, default is /{env}/{service}")
+	varPrefix := fs.String("var-prefix", "", "prefix to add to each environment variable, e.g. APP_")
+	allowMissing := fs.Bool("allow-missing", false, "skip parameters in the report file that do not exist")
+
+	argv, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(argv) == 0 {
+		return errors.New("a command to execute is required")
+	}
+
+	envOpts := ssm.EnvOptions{Prefixes: parseList(*paths), AllowMissing: *allowMissing}
+
+	if opts.file != "" {
+		if envOpts.Report, err = opts.readReport(); err != nil {
+			return err
+		}
+	}
+
+	if *trim == "" {
+		*trim = opts.base()
+	}
+
+	naming := ssm.TrimPrefixNaming(*trim)
+	envOpts.Naming = func(name string) string { return *varPrefix + naming(name) }
+
+	env, err := opts.serializer().Environ(envOpts)
+	if err != nil {
+		return err
+	}
+
+	return execve(argv, mergeEnv(os.Environ(), env))
+}
+
+// mergeEnv adds the config variables to the environment where config takes precedence
+func mergeEnv(environ []string, config []string) []string {
+	keys := map[string]bool{}
+	for _, kv := range config {
+		keys[kv[:strings.Index(kv, "=")]] = true
+	}
+
+	merged := make([]string, 0, len(environ)+len(config))
+	for _, kv := range environ {
+		if i := strings.Index(kv, "="); i < 0 || !keys[kv[:i]] {
+			merged = append(merged, kv)
+		}
+	}
+
+	return append(merged, config...)
+}
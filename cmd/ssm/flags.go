@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"plugin"
+	"strings"
+
+	"github.com/mariotoffia/ssm"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/pkg/errors"
+)
+
+// errUsage is returned when the flags or arguments are invalid and the usage is printed
+var errUsage = errors.New("invalid usage")
+
+// options is the flags shared by the sub commands
+type options struct {
+	env     string
+	service string
+	prefix  string
+	output  string
+	store   string
+	file    string
+	plugin  string
+	symbol  string
+}
+
+// newFlags creates the flag set with the shared flags. The environment and service
+// defaults to the SSM_ENV and SSM_SERVICE environment variables.
+func newFlags(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	fs.StringVar(&opts.env, "env", os.Getenv("SSM_ENV"), "environment, e.g. dev or prod")
+	fs.StringVar(&opts.service, "service", os.Getenv("SSM_SERVICE"), "service name")
+	fs.StringVar(&opts.prefix, "prefix", "", "prefix, global when starting with a slash")
+	fs.StringVar(&opts.output, "output", formatTable, "output format: json, table or env")
+
+	return fs
+}
+
+// storeFlag adds the store flag used when creating parameters out of names
+func storeFlag(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.store, "store", "pms", "store of the names: pms (parameter store) or asm (secrets manager)")
+}
+
+// sourceFlags adds the flags to read a report from file or a go plugin
+func sourceFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.file, "file", "", "report JSON file")
+	fs.StringVar(&opts.plugin, "plugin", "", "go plugin that exports the tagged struct")
+	fs.StringVar(&opts.symbol, "symbol", "", "name of the exported struct variable in the plugin")
+}
+
+// parse parses the flags and returns the positional arguments
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+
+	return fs.Args(), nil
+}
+
+func (o *options) serializer() *ssm.Serializer {
+	return ssm.NewSsmSerializer(o.env, o.service).UsePrefix(o.prefix)
+}
+
+// base is the rendered prefix that relative names are put under
+func (o *options) base() string {
+	return parser.RenderPrefix(o.prefix, o.env, o.service)
+}
+
+// resolve renders the full name. Names starting with a slash are already full names.
+func (o *options) resolve(name string) string {
+	if strings.HasPrefix(name, "/") {
+		return name
+	}
+
+	return o.base() + "/" + name
+}
+
+// parameterType returns the report type of the store flag
+func (o *options) parameterType() (report.ParameterType, error) {
+	switch o.store {
+	case "pms":
+		return report.ParameterStore, nil
+	case "asm":
+		return report.SecretsManager, nil
+	}
+
+	return "", errors.Errorf("unknown store %s, use pms or asm", o.store)
+}
+
+// parameters creates one parameter per name in the store
+func (o *options) parameters(names []string) ([]report.Parameter, error) {
+	tp, err := o.parameterType()
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]report.Parameter, 0, len(names))
+	for _, name := range names {
+		prm := report.Parameter{Type: tp, Name: o.resolve(name)}
+
+		if tp == report.ParameterStore {
+			prm.Details = report.PmsParameterDetails{}
+		} else {
+			prm.Details = report.AsmParameterDetails{}
+		}
+
+		params = append(params, prm)
+	}
+
+	return params, nil
+}
+
+// hasSource returns true when either a file or a plugin is specified
+func (o *options) hasSource() bool {
+	return o.file != "" || o.plugin != ""
+}
+
+// readReport reads the report file
+func (o *options) readReport() (*report.Report, error) {
+	data, err := ioutil.ReadFile(o.file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rpt report.Report
+	if err := json.Unmarshal(data, &rpt); err != nil {
+		return nil, errors.Wrapf(err, "%s is not a report", o.file)
+	}
+
+	return &rpt, nil
+}
+
+// lookupStruct opens the plugin and returns the pointer to the exported struct
+func (o *options) lookupStruct() (interface{}, error) {
+	if o.plugin == "" || o.symbol == "" {
+		return nil, errors.New("both -plugin and -symbol are required")
+	}
+
+	p, err := plugin.Open(o.plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	sym, err := p.Lookup(o.symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return sym, nil
+}
+
+// loadReport reads the report from file, or renders it from the plugin struct
+func (o *options) loadReport(values bool) (*report.Report, error) {
+	if o.file != "" {
+		return o.readReport()
+	}
+
+	v, err := o.lookupStruct()
+	if err != nil {
+		return nil, err
+	}
+
+	rpt, _, err := o.serializer().ReportWithOpts(v, ssm.NoFilter, values)
+	return rpt, err
+}
+
+// parseTags parses key=value pairs separated by comma
+func parseTags(s string) (map[string]string, error) {
+	tags := map[string]string{}
+	if s == "" {
+		return tags, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("tag %s is not on the form key=value", pair)
+		}
+
+		tags[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return tags, nil
+}
+
+// parseList splits a comma separated list
+func parseList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
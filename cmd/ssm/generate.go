@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// runGenerate generates go structs out of the parameters and secrets under the prefixes
+func runGenerate(args []string, stdout io.Writer) error {
+	opts := &options{}
+	fs := newFlags("generate", opts)
+	paths := fs.String("path", "", "comma separated prefixes to list, default is /{env}/{service}/")
+	pkg := fs.String("package", "config", "go package name")
+	name := fs.String("name", "Config", "root struct name")
+	out := fs.String("o", "", "file to write, default is stdout")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	src, err := opts.serializer().GenerateStructs(*pkg, *name, parseList(*paths)...)
+	if err != nil {
+		return err
+	}
+
+	skipped := make([]string, 0, len(src.Skipped))
+	for skip := range src.Skipped {
+		skipped = append(skipped, skip)
+	}
+
+	sort.Strings(skipped)
+
+	for _, skip := range skipped {
+		fmt.Fprintf(os.Stderr, "skipped %s: %s\n", skip, src.Skipped[skip])
+	}
+
+	if *out != "" {
+		return ioutil.WriteFile(*out, src.Source, 0644)
+	}
+
+	_, err = stdout.Write(src.Source)
+	return err
+}
@@ -0,0 +1,90 @@
+// Command ssm reads, writes, reports and deletes parameter store parameters and
+// secrets manager secrets without any go code. It also executes commands with the
+// parameters as environment variables. Names that do not start with a slash
+// are rendered using the same prefix rules as the tags, i.e. /{env}/{service}/{name}
+// or /{env}/{prefix}/{name} when the prefix is global.
+//
+//	ssm get -env dev -service my-service db/host
+//	ssm put -env dev -service my-service -secure db/password s3cr3t
+//	ssm report -file report.json -remote -output table
+//	ssm apply -env prod -dry-run -file report.json
+//	ssm delete -env dev -store asm -commit /dev/my-service/legacy
+//	ssm prune -env dev -service my-service -plugin config.so -symbol Config
+//	ssm diff -from dev -to prod -file report.json -values
+//	ssm exec -env prod -service my-service -- ./legacy-binary -v
+//	ssm generate -env dev -service my-service -package config -o config.go
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a single sub command
+type command struct {
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"get":      {"get [flags] name...", runGet},
+	"put":      {"put [flags] name value", runPut},
+	"report":   {"report [flags] (-file report.json | -plugin file.so -symbol Name)", runReport},
+	"apply":    {"apply [flags] [-dry-run] [-force] -file report.json", runApply},
+	"delete":   {"delete [flags] [-file report.json] [name...]", runDelete},
+	"prune":    {"prune [flags] -plugin file.so -symbol Name", runPrune},
+	"diff":     {"diff [flags] -from env -to env (-file report.json | -plugin file.so -symbol Name)", runDiff},
+	"exec":     {"exec [flags] [-file report.json] [-path prefix,...] -- command [args...]", runExec},
+	"generate": {"generate [flags] [-path prefix,...] [-package name] [-name Struct] [-o file.go]", runGenerate},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the sub command in args[0] and returns the exit code
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %s\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(args[1:], stdout); err != nil {
+		if err != errUsage {
+			fmt.Fprintf(stderr, "%s: %v\n", args[0], err)
+		}
+
+		fmt.Fprintf(stderr, "usage: ssm %s\n", cmd.usage)
+		return 1
+	}
+
+	return 0
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: ssm <command> [flags]")
+	fmt.Fprintln(w)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  ssm %s\n", commands[name].usage)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use ssm <command> -h for the flags of a command.")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRelativeAndFullNames(t *testing.T) {
+	opts := &options{env: "dev", service: "test-service"}
+	assert.Equal(t, "/dev/test-service/db/host", opts.resolve("db/host"))
+	assert.Equal(t, "/prod/other/name", opts.resolve("/prod/other/name"))
+
+	opts.prefix = "/global"
+	assert.Equal(t, "/dev/global/db/host", opts.resolve("db/host"))
+}
+
+func TestParametersUsesStore(t *testing.T) {
+	opts := &options{env: "dev", service: "test-service", store: "asm"}
+
+	params, err := opts.parameters([]string{"db"})
+	assert.NoError(t, err)
+	assert.Equal(t, report.SecretsManager, params[0].Type)
+	assert.Equal(t, "/dev/test-service/db", params[0].Name)
+
+	opts.store = "s3"
+	_, err = opts.parameters([]string{"db"})
+	assert.Error(t, err)
+}
+
+func TestMergeEnvConfigTakesPrecedence(t *testing.T) {
+	merged := mergeEnv([]string{"PATH=/bin", "DB_HOST=local"}, []string{"DB_HOST=remote", "DB_PORT=5432"})
+	assert.Equal(t, []string{"PATH=/bin", "DB_HOST=remote", "DB_PORT=5432"}, merged)
+}
+
+func TestExecRequiresCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 1, run([]string{"exec", "-env", "dev"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "a command to execute is required")
+}
+
+func TestWriteReportFormats(t *testing.T) {
+	rpt := &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/test-service/name", ValueType: "String",
+			Value: "it's", Version: "2"},
+		{Type: report.SecretsManager, Name: "/dev/test-service/missing", Missing: true},
+	}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeReport(&buf, rpt, formatEnv, "/dev/test-service"))
+	assert.Equal(t, "NAME='it'\\''s'\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, writeReport(&buf, rpt, formatTable, "/dev/test-service"))
+	assert.Contains(t, buf.String(), "NAME")
+	assert.Contains(t, buf.String(), "/dev/test-service/missing")
+	assert.Contains(t, buf.String(), "<missing>")
+
+	buf.Reset()
+	assert.NoError(t, writeReport(&buf, rpt, formatJSON, "/dev/test-service"))
+	assert.Contains(t, buf.String(), `"fqname": "/dev/test-service/name"`)
+
+	assert.Error(t, writeReport(&buf, rpt, "xml", ""))
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := parseTags("env=dev, team = core")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "dev", "team": "core"}, tags)
+
+	_, err = parseTags("env")
+	assert.Error(t, err)
+}
+
+func TestTierOf(t *testing.T) {
+	tier, err := tierOf("advanced")
+	assert.NoError(t, err)
+	assert.Equal(t, types.ParameterTierAdvanced, tier)
+
+	_, err = tierOf("premium")
+	assert.Error(t, err)
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 2, run([]string{}, &stdout, &stderr))
+	assert.Equal(t, 2, run([]string{"nope"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "unknown command nope")
+}
+
+func TestRunReportsErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 1, run([]string{"get"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "at least one name is required")
+	assert.Contains(t, stderr.String(), "usage: ssm get")
+
+	stderr.Reset()
+	assert.Equal(t, 1, run([]string{"apply", "-nosuchflag"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "usage: ssm apply")
+}
+
+func TestDeleteDryRunPrintsNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssm")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "report.json")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(
+		`{"parameters":[{"type":"secrets-manager","fqname":"/dev/test-service/db"}]}`), 0600))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, run([]string{"delete", "-env", "dev", "-service", "test-service",
+		"-file", file, "name"}, &stdout, &stderr), stderr.String())
+	assert.Equal(t, "/dev/test-service/name\n/dev/test-service/db\n", stdout.String())
+}
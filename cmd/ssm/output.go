@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mariotoffia/ssm"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/pkg/errors"
+)
+
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+	formatEnv   = "env"
+)
+
+// writeReport writes the report in the format. The env format names the variables
+// after the full name relative to base, see ssm.TrimPrefixNaming.
+func writeReport(w io.Writer, rpt *report.Report, format string, base string) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, rpt)
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tTYPE\tVALUETYPE\tVERSION\tVALUE")
+
+		for _, prm := range rpt.Parameters {
+			value := prm.Value
+			if prm.Missing {
+				value = "<missing>"
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", prm.Name, prm.Type, prm.ValueType, prm.Version,
+				strings.NewReplacer("\n", `\n`, "\t", `\t`).Replace(value))
+		}
+
+		return tw.Flush()
+	case formatEnv:
+		for _, prm := range rpt.Parameters {
+			if prm.Missing {
+				continue
+			}
+
+			fmt.Fprintf(w, "%s=%s\n", ssm.TrimPrefixNaming(base)(prm.Name), shellQuote(prm.Value))
+		}
+
+		return nil
+	}
+
+	return errors.Errorf("unknown output format %s, use json, table or env", format)
+}
+
+// writeFailed writes the names that failed, sorted by name, in the format
+func writeFailed(w io.Writer, failed map[string]error, format string) error {
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	if format == formatJSON {
+		out := map[string]string{}
+		for _, name := range names {
+			out[name] = failed[name].Error()
+		}
+
+		return writeJSON(w, out)
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %v\n", name, failed[name])
+	}
+
+	return nil
+}
+
+// writeNames writes one name per line, or a JSON array
+func writeNames(w io.Writer, names []string, format string) error {
+	if format == formatJSON {
+		return writeJSON(w, names)
+	}
+
+	for _, name := range names {
+		fmt.Fprintln(w, name)
+	}
+
+	return nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// shellQuote quotes the value using single quotes
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,67 @@
+// Command ssmgen generates code that parses, populates and marshals structs with pms
+// and asm tags without reflection (see package codegen). It is typically run using
+// go generate in the package declaring the structs:
+//
+//	//go:generate go run github.com/mariotoffia/ssm/cmd/ssmgen -type Config,Other
+//
+// The code is written to <type>_ssm.go, in lower case, unless -output is set.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mariotoffia/ssm/codegen"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+// run generates the code and returns the exit code
+func run(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ssmgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	types := fs.String("type", "", "comma separated list of struct type names (required)")
+	output := fs.String("output", "", "output file name, default <type>_ssm.go")
+
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: ssmgen -type T [-output file] [directory]\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *types == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	names := strings.Split(*types, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+
+	file := *output
+	if file == "" {
+		file = strings.ToLower(names[0]) + "_ssm.go"
+	}
+
+	if err := codegen.WriteFile(dir, file, names...); err != nil {
+		fmt.Fprintf(stderr, "ssmgen: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
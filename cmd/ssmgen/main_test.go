@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRequiresType(t *testing.T) {
+	var stderr bytes.Buffer
+	assert.Equal(t, 2, run([]string{"."}, &stderr))
+	assert.Contains(t, stderr.String(), "usage: ssmgen")
+}
+
+func TestRunWritesTypeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssmgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := "package config\n\ntype Config struct {\n\tName string `pms:\"name\"`\n}\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0644))
+
+	var stderr bytes.Buffer
+	assert.Equal(t, 0, run([]string{"-type", "Config", dir}, &stderr), stderr.String())
+
+	code, err := ioutil.ReadFile(filepath.Join(dir, "config_ssm.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), "func (v *Config) SsmPopulate(field string, value string) (bool, error)")
+
+	assert.Equal(t, 1, run([]string{"-type", "Missing", dir}, &stderr))
+}
@@ -0,0 +1,442 @@
+// Package codegen generates code that parses, populates and marshals structs with pms
+// and asm tags without reflection. The generated methods implements parser.Generated
+// and are picked up by all Serializer operations. Use it via the ssmgen command:
+//
+//	//go:generate go run github.com/mariotoffia/ssm/cmd/ssmgen -type Config
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/pkg/errors"
+)
+
+// kind is how a field is populated and marshalled by the generated code
+type kind int
+
+const (
+	// reflected fields are parsed and set using reflection
+	reflected kind = iota
+	stringKind
+	intKind
+	uintKind
+	boolKind
+	float32Kind
+	float64Kind
+	stringListKind
+	// structKind is a nested struct that is populated and marshalled as JSON
+	structKind
+)
+
+// builtins maps the supported builtin types, the same kinds that are set using
+// reflection, to their kind.
+var builtins = map[string]kind{
+	"string": stringKind,
+	"int":    intKind, "int8": intKind, "int32": intKind, "int64": intKind, "rune": intKind,
+	"uint": uintKind, "uint8": uintKind, "uint16": uintKind, "uint32": uintKind,
+	"uint64": uintKind, "byte": uintKind,
+	"bool":    boolKind,
+	"float32": float32Kind,
+	"float64": float64Kind,
+}
+
+type generator struct {
+	specs    map[string]*ast.TypeSpec
+	imports  map[string]bool
+	populate bytes.Buffer
+	marshal  bytes.Buffer
+}
+
+// GenerateDir generates the code of the named struct types declared in the go files,
+// excluding tests, in the directory.
+func GenerateDir(dir string, types ...string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := goparser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, goparser.ParseComments)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse %s", dir)
+	}
+
+	if len(pkgs) != 1 {
+		return nil, errors.Errorf("Expected a single package in %s, got %d", dir, len(pkgs))
+	}
+
+	files := []*ast.File{}
+	for _, pkg := range pkgs {
+		names := make([]string, 0, len(pkg.Files))
+		for name := range pkg.Files {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			files = append(files, pkg.Files[name])
+		}
+	}
+
+	return Generate(files, types...)
+}
+
+// Generate generates the code of the named struct types declared in the files of a
+// single package. For each type it generates the methods of parser.Generated: the
+// fields along with their tags, from which the remote names are rendered, a populate
+// and a marshal function.
+//
+// Fields of string, integer, unsigned integer, bool, float and []string types are set
+// and rendered using typed code, as are nested structs declared in the package or
+// inline (those are JSON values when tagged). All other fields, e.g. of types declared
+// in other packages such as support.RotatingSecret, fall back on reflection. The pms and
+// asm tags are validated using the same tag parsers as when parsing using reflection.
+func Generate(files []*ast.File, types ...string) ([]byte, error) {
+	if len(files) == 0 {
+		return nil, errors.New("No files to generate from")
+	}
+
+	if len(types) == 0 {
+		return nil, errors.New("At least one type is required")
+	}
+
+	g := &generator{specs: map[string]*ast.TypeSpec{}, imports: map[string]bool{}}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					g.specs[ts.Name.Name] = ts
+				}
+			}
+		}
+	}
+
+	var body bytes.Buffer
+
+	for _, name := range types {
+		code, err := g.generateType(name)
+		if err != nil {
+			return nil, err
+		}
+
+		body.Write(code)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"ssmgen -type %s\"; DO NOT EDIT.\n\n", strings.Join(types, ","))
+	fmt.Fprintf(&buf, "package %s\n\n", files[0].Name.Name)
+
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+
+	sort.Strings(imports)
+
+	buf.WriteString("import (\n")
+	for _, imp := range imports {
+		if !strings.Contains(imp, ".") {
+			fmt.Fprintf(&buf, "%q\n", imp)
+		}
+	}
+
+	buf.WriteString("\n")
+	for _, imp := range imports {
+		if strings.Contains(imp, ".") {
+			fmt.Fprintf(&buf, "%q\n", imp)
+		}
+	}
+
+	buf.WriteString(")\n\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to format generated source")
+	}
+
+	return src, nil
+}
+
+// generateType generates the parser.Generated methods of the named struct type
+func (g *generator) generateType(name string) ([]byte, error) {
+	spec, ok := g.specs[name]
+	if !ok {
+		return nil, errors.Errorf("Type %s is not declared in the package", name)
+	}
+
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok || spec.Assign != token.NoPos {
+		return nil, errors.Errorf("Type %s is not a struct", name)
+	}
+
+	g.populate.Reset()
+	g.marshal.Reset()
+	g.imports["github.com/mariotoffia/ssm/parser"] = true
+
+	fields, err := g.fields(st, "", "v.", map[string]bool{name: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to generate %s", name)
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// SsmFields implements parser.Generated.\n")
+	fmt.Fprintf(&buf, "func (v *%s) SsmFields() []parser.GeneratedField {\n", name)
+	fmt.Fprintf(&buf, "return %s\n}\n\n", fields)
+
+	fmt.Fprintf(&buf, "// SsmPopulate implements parser.Generated.\n")
+	fmt.Fprintf(&buf, "func (v *%s) SsmPopulate(field string, value string) (bool, error) {\n", name)
+	if g.populate.Len() > 0 {
+		fmt.Fprintf(&buf, "switch field {\n%sdefault:\nreturn false, nil\n}\n\nreturn true, nil\n}\n\n",
+			g.populate.String())
+	} else {
+		fmt.Fprintf(&buf, "return false, nil\n}\n\n")
+	}
+
+	fmt.Fprintf(&buf, "// SsmMarshal implements parser.Generated.\n")
+	fmt.Fprintf(&buf, "func (v *%s) SsmMarshal(field string) (string, bool) {\n", name)
+	if g.marshal.Len() > 0 {
+		fmt.Fprintf(&buf, "switch field {\n%s}\n\n", g.marshal.String())
+	}
+
+	fmt.Fprintf(&buf, "return \"\", false\n}\n\n")
+
+	return buf.Bytes(), nil
+}
+
+// fields renders the parser.GeneratedField list of the struct and adds the populate
+// and marshal cases of each field. The path is the fully qualified field name prefix,
+// e.g. Sub., and expr is the go expression prefix, e.g. v.Sub., of the struct.
+func (g *generator) fields(st *ast.StructType, path string, expr string,
+	parents map[string]bool) (string, error) {
+
+	var buf bytes.Buffer
+	buf.WriteString("[]parser.GeneratedField{\n")
+
+	for _, field := range st.Fields.List {
+		names := []string{}
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+		}
+
+		if len(names) == 0 {
+			names = append(names, embeddedName(field.Type))
+		}
+
+		tag := ""
+		if field.Tag != nil {
+			tag = field.Tag.Value
+			if err := validateTag(tag); err != nil {
+				return "", errors.Wrapf(err, "Invalid tag on field %s%s", path, names[0])
+			}
+		}
+
+		for _, name := range names {
+			if name == "_" || name == "" {
+				return "", errors.Errorf("Field %s%s is not supported", path, name)
+			}
+
+			g.imports["reflect"] = true
+
+			fmt.Fprintf(&buf, "{Name: %q, ", name)
+			if tag != "" {
+				fmt.Fprintf(&buf, "Tag: %s, ", tag)
+			}
+
+			fmt.Fprintf(&buf, "Value: reflect.ValueOf(&%s%s).Elem()", expr, name)
+
+			k, nested := g.kind(field.Type, parents)
+			if k != reflected {
+				g.addCases(k, field.Type, path+name, expr+name)
+			}
+
+			switch {
+			case nested != nil:
+				children, err := g.fields(nested, path+name+".", expr+name+".", g.withParent(parents, field.Type))
+				if err != nil {
+					return "", err
+				}
+
+				fmt.Fprintf(&buf, ",\nFields: %s", children)
+			case k == reflected:
+				buf.WriteString(", Reflect: true")
+			}
+
+			buf.WriteString("},\n")
+		}
+	}
+
+	buf.WriteString("}")
+	return buf.String(), nil
+}
+
+// kind returns the kind of the type and, when a nested struct, the struct
+func (g *generator) kind(expr ast.Expr, parents map[string]bool) (kind, *ast.StructType) {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return structKind, t
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && ident.Name == "string" &&
+			g.specs[ident.Name] == nil {
+			return stringListKind, nil
+		}
+	case *ast.Ident:
+		if spec, ok := g.specs[t.Name]; ok {
+			if st, ok := spec.Type.(*ast.StructType); ok && spec.Assign == token.NoPos && !parents[t.Name] {
+				return structKind, st
+			}
+
+			return reflected, nil
+		}
+
+		if k, ok := builtins[t.Name]; ok {
+			return k, nil
+		}
+	}
+
+	return reflected, nil
+}
+
+// withParent returns the parents including the named type, if any, to detect recursion
+func (g *generator) withParent(parents map[string]bool, expr ast.Expr) map[string]bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return parents
+	}
+
+	m := map[string]bool{ident.Name: true}
+	for name := range parents {
+		m[name] = true
+	}
+
+	return m
+}
+
+// addCases adds the populate and marshal case of the field. Those mirrors
+// common.SetStructValueFromString and common.GetStringValueFromField.
+func (g *generator) addCases(k kind, expr ast.Expr, field string, x string) {
+	typ := ""
+	if ident, ok := expr.(*ast.Ident); ok {
+		typ = ident.Name
+	}
+
+	fmt.Fprintf(&g.populate, "case %q:\n", field)
+	fmt.Fprintf(&g.marshal, "case %q:\n", field)
+
+	switch k {
+	case stringKind:
+		fmt.Fprintf(&g.populate, "%s = value\n", x)
+		fmt.Fprintf(&g.marshal, "return %s, true\n", x)
+	case intKind:
+		g.imports["strconv"] = true
+		fmt.Fprintf(&g.populate, "i, err := strconv.ParseInt(value, 10, 64)\n%s\n%s = %s\n",
+			checkErr, x, convert(typ, "int64", "i"))
+		fmt.Fprintf(&g.marshal, "return strconv.FormatInt(%s, 10), true\n", convert("int64", typ, x))
+	case uintKind:
+		g.imports["strconv"] = true
+		fmt.Fprintf(&g.populate, "u, err := strconv.ParseUint(value, 10, 64)\n%s\n%s = %s\n",
+			checkErr, x, convert(typ, "uint64", "u"))
+		fmt.Fprintf(&g.marshal, "return strconv.FormatUint(%s, 10), true\n", convert("uint64", typ, x))
+	case boolKind:
+		g.imports["strconv"] = true
+		fmt.Fprintf(&g.populate, "b, err := strconv.ParseBool(value)\n%s\n%s = b\n", checkErr, x)
+		fmt.Fprintf(&g.marshal, "return strconv.FormatBool(%s), true\n", x)
+	case float32Kind, float64Kind:
+		g.imports["strconv"] = true
+		bits := "64"
+		if k == float32Kind {
+			bits = "32"
+		}
+
+		fmt.Fprintf(&g.populate, "f, err := strconv.ParseFloat(value, 64)\n%s\n%s = %s\n",
+			checkErr, x, convert(typ, "float64", "f"))
+		fmt.Fprintf(&g.marshal, "return strconv.FormatFloat(%s, 'f', -1, %s), true\n",
+			convert("float64", typ, x), bits)
+	case stringListKind:
+		g.imports["strings"] = true
+		fmt.Fprintf(&g.populate, "%s = strings.Split(value, \",\")\n", x)
+		fmt.Fprintf(&g.marshal, "return strings.Join(%s, \",\"), true\n", x)
+	case structKind:
+		g.imports["encoding/json"] = true
+		fmt.Fprintf(&g.populate, "return true, json.Unmarshal([]byte(value), &%s)\n", x)
+		fmt.Fprintf(&g.marshal, "data, _ := json.Marshal(&%s)\nreturn string(data), true\n", x)
+	}
+}
+
+const checkErr = "if err != nil {\nreturn true, err\n}"
+
+// convert renders the conversion of x, of type from, to the type to
+func convert(to string, from string, x string) string {
+	if to == from {
+		return x
+	}
+
+	return fmt.Sprintf("%s(%s)", to, x)
+}
+
+// embeddedName returns the field name of an embedded field
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+
+	return ""
+}
+
+// validateTag parses the pms and asm tags, of the field tag literal, using the same
+// tag parsers as when parsing the struct.
+func validateTag(literal string) error {
+	tag, err := strconv.Unquote(literal)
+	if err != nil {
+		return err
+	}
+
+	parsers := map[string]parser.TagParser{"pms": pms.NewTagParser(), "asm": asm.NewTagParser()}
+
+	for name, tp := range parsers {
+		if tagstring, ok := reflect.StructTag(tag).Lookup(name); ok {
+			if _, err := tp.ParseTagString(tagstring, "", "env", "svc"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteFile generates the code of the types, declared in the directory, and writes
+// it to the file. If the file is not absolute it is relative to the directory.
+func WriteFile(dir string, file string, types ...string) error {
+	src, err := GenerateDir(dir, types...)
+	if err != nil {
+		return err
+	}
+
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+
+	return ioutil.WriteFile(file, src, 0644)
+}
@@ -0,0 +1,216 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/internal/testsupport"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+// reflective has the same fields as the generated struct but no generated methods
+type reflective testsupport.GeneratedStruct
+
+// values is the remote value of each field in GeneratedStruct
+var values = map[string]string{
+	"Name":           "The name",
+	"Count":          "42",
+	"Small":          "-7",
+	"Port":           "8080",
+	"Enabled":        "true",
+	"Ratio":          "0.25",
+	"Weight":         "1.5",
+	"Hosts":          "a,b,c",
+	"Password":       "s3cr3t",
+	"Unknown":        "12",
+	"Sub.Host":       "localhost",
+	"Sub.Deep.Level": "3",
+	"Inline.Apa":     "99",
+	"Inline.Nu":      "nu",
+	"Settings":       `{"batchsize":77,"signer":"mto"}`,
+	"Rds":            `{"user":"admin","password":"pwd","port":5432}`,
+	"User":           "kalle",
+	"Timeout":        "30",
+	"Rotating":       "current",
+}
+
+func parse(t *testing.T, v interface{}) *parser.StructNode {
+	node, err := parser.New("test-service", "dev", "").
+		RegisterTagParser("pms", pms.NewTagParser()).
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(reflect.ValueOf(v))
+
+	assert.NoError(t, err)
+	return node
+}
+
+// describe renders each node, in the tree, on a single line
+func describe(node *parser.StructNode, lines []string) []string {
+	for i := range node.Childs {
+		child := &node.Childs[i]
+		line := fmt.Sprintf("%s %s %s %v", child.FqName, child.Field.Name, child.Field.Tag, child.Value.Type())
+
+		names := []string{}
+		for name, tag := range child.Tag {
+			names = append(names, fmt.Sprintf("%s=%s %v %v", name, tag.GetFullName(), tag.GetNamed(), tag.GetTags()))
+		}
+
+		sort.Strings(names)
+		lines = describe(child, append(lines, line+" "+strings.Join(names, " ")))
+	}
+
+	return lines
+}
+
+// nodes returns all nodes in the tree keyed by the fully qualified field name
+func nodes(node *parser.StructNode, m map[string]*parser.StructNode) map[string]*parser.StructNode {
+	for i := range node.Childs {
+		m[node.Childs[i].FqName] = &node.Childs[i]
+		nodes(&node.Childs[i], m)
+	}
+
+	return m
+}
+
+func TestGeneratedFileIsUpToDate(t *testing.T) {
+	src, err := GenerateDir("../internal/testsupport", "GeneratedStruct")
+	assert.NoError(t, err)
+
+	expected, err := ioutil.ReadFile("../internal/testsupport/generatedstruct_ssm.go")
+	assert.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(src), "run go generate ./internal/testsupport")
+}
+
+func TestGeneratedParsesAsReflection(t *testing.T) {
+	var generated testsupport.GeneratedStruct
+	gnode := parse(t, &generated)
+	rnode := parse(t, (*reflective)(&generated))
+
+	assert.Equal(t, describe(rnode, nil), describe(gnode, nil))
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(gnode, m, support.NewFilters(), []string{"pms", "asm"})
+	assert.Contains(t, m, "/dev/test-service/simple/test")
+	assert.Contains(t, m, "/dev/global/myname")
+	assert.Contains(t, m, "/dev/test-service/sub/deep/level")
+
+	assert.NotNil(t, nodes(gnode, map[string]*parser.StructNode{})["Sub.Deep.Level"].Generated)
+	assert.Nil(t, nodes(gnode, map[string]*parser.StructNode{})["Rotating"].Generated)
+	assert.Nil(t, nodes(rnode, map[string]*parser.StructNode{})["Name"].Generated)
+}
+
+func TestGeneratedPopulatesAndMarshalsAsReflection(t *testing.T) {
+	var generated, reflected testsupport.GeneratedStruct
+	gnodes := nodes(parse(t, &generated), map[string]*parser.StructNode{})
+	rnodes := nodes(parse(t, (*reflective)(&reflected)), map[string]*parser.StructNode{})
+
+	for field, value := range values {
+		assert.NoError(t, common.SetStructValueFromString(rnodes[field], field, value), field)
+		assert.NoError(t, common.SetStructValueFromString(gnodes[field], field, value), field)
+	}
+
+	assert.Equal(t, reflected, generated)
+	assert.Equal(t, uint16(8080), generated.Port)
+	assert.Equal(t, []string{"a", "b", "c"}, generated.Hosts)
+	assert.Equal(t, "mto", generated.Settings.Signer)
+	assert.Equal(t, int16(0), generated.Unknown)
+
+	for field := range rnodes {
+		assert.Equal(t, common.GetStringValueFromField(rnodes[field]),
+			common.GetStringValueFromField(gnodes[field]), field)
+	}
+
+	for _, field := range []string{"Count", "Port", "Enabled", "Ratio", "Rds"} {
+		assert.Error(t, common.SetStructValueFromString(rnodes[field], field, "invalid"), field)
+		assert.Error(t, common.SetStructValueFromString(gnodes[field], field, "invalid"), field)
+	}
+
+	assert.Equal(t, reflected, generated)
+}
+
+func generate(t *testing.T, src string, types ...string) (string, error) {
+	file, err := goparser.ParseFile(token.NewFileSet(), "config.go", src, 0)
+	assert.NoError(t, err)
+
+	code, err := Generate([]*ast.File{file}, types...)
+	return string(code), err
+}
+
+func TestGenerateFallsBackOnReflection(t *testing.T) {
+	code, err := generate(t, `package config
+
+import "time"
+
+type Level string
+
+type Base struct {
+	Region string `+"`pms:\"region\"`"+`
+}
+
+type Config struct {
+	Base
+	Level   Level          `+"`pms:\"level\"`"+`
+	Ptr     *Base
+	Started time.Time
+	Labels  map[string]string
+	Self    []Config
+	Bytes   []byte
+}
+`, "Config")
+
+	assert.NoError(t, err)
+	code = strings.Join(strings.Fields(code), " ")
+
+	assert.Contains(t, code, `{Name: "Base", Value: reflect.ValueOf(&v.Base).Elem(), Fields:`)
+	assert.Contains(t, code, `case "Base.Region": v.Base.Region = value`)
+	assert.Contains(t, code, `{Name: "Level", Tag: `+"`pms:\"level\"`"+`, Value: reflect.ValueOf(&v.Level).Elem(), Reflect: true}`)
+	assert.Contains(t, code, `{Name: "Ptr", Value: reflect.ValueOf(&v.Ptr).Elem(), Reflect: true}`)
+	assert.Contains(t, code, `{Name: "Started", Value: reflect.ValueOf(&v.Started).Elem(), Reflect: true}`)
+	assert.Contains(t, code, `{Name: "Labels", Value: reflect.ValueOf(&v.Labels).Elem(), Reflect: true}`)
+	assert.Contains(t, code, `{Name: "Self", Value: reflect.ValueOf(&v.Self).Elem(), Reflect: true}`)
+	assert.Contains(t, code, `{Name: "Bytes", Value: reflect.ValueOf(&v.Bytes).Elem(), Reflect: true}`)
+	assert.NotContains(t, code, `case "Level"`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	src := `package config
+
+type Level string
+
+type Config struct {
+	Name string ` + "`pms:\"name, other\"`" + `
+}
+
+type Blank struct {
+	_ int
+}
+`
+
+	_, err := generate(t, src, "Missing")
+	assert.Error(t, err)
+
+	_, err = generate(t, src, "Level")
+	assert.Error(t, err)
+
+	_, err = generate(t, src, "Config")
+	assert.Error(t, err)
+
+	_, err = generate(t, src, "Blank")
+	assert.Error(t, err)
+
+	_, err = generate(t, src)
+	assert.Error(t, err)
+}
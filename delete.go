@@ -74,3 +74,23 @@ func (s *Serializer) delete(v interface{},
 
 	return invalid, node, err
 }
+
+func (s *Serializer) restore(v interface{},
+	filter *support.FieldFilters) (map[string]support.FullNameField, error) {
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	node, err := s.parseAsm(v)
+	if err != nil {
+		return nil, err
+	}
+
+	asmRepository, err := s.getAndConfigureAsm()
+	if err != nil {
+		return nil, err
+	}
+
+	return asmRepository.Restore(node, filter)
+}
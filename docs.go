@@ -0,0 +1,18 @@
+package ssm
+
+import (
+	"github.com/mariotoffia/ssm/docs"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// DocsWithOpts generates the configuration reference of the in param struct. The values
+// of the struct are documented as defaults, except for secure fields that never has a
+// value in the documentation.
+func (s *Serializer) DocsWithOpts(v interface{}, filter *support.FieldFilters, title string) (*docs.Document, error) {
+	rpt, _, node, err := s.report(v, filter, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return docs.Build(title, node, rpt), nil
+}
@@ -0,0 +1,186 @@
+// Package docs generates a configuration reference, as Markdown or HTML, out of a
+// parsed node tree and the report of the same tree. The fields are grouped by sub-struct
+// and rendered in declaration order, hence the output is stable enough to be committed.
+// Values of secure parameters and secrets are never part of the documentation.
+package docs
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+)
+
+// Document is the configuration reference
+type Document struct {
+	// Title is the document title
+	Title string
+	// Sections is the fields grouped by sub-struct in declaration order
+	Sections []Section
+}
+
+// Section is all fields directly within a struct
+type Section struct {
+	// Name is the dotted navigation to the sub-struct, empty for the root struct
+	Name string
+	// Type is the go type of the struct
+	Type string
+	// Fields is the fields in declaration order
+	Fields []Field
+}
+
+// Field is a single documented field
+type Field struct {
+	// FqName is the go path, e.g. Db.Host
+	FqName string
+	// Name is the remote name, e.g. /prod/test-service/db/host
+	Name string
+	// JSONKey is set when the field reads a key out of a JSON document
+	JSONKey string
+	// Store is the tag that backs the field, e.g. pms or asm
+	Store string
+	// Type is the go type of the field
+	Type string
+	// ValueType is the remote value type, e.g. String or SecureString
+	ValueType string
+	// Tier is the parameter store tier, empty for secrets
+	Tier string
+	// Description is the tag description
+	Description string
+	// Secure is true for secrets and encrypted parameters
+	Secure bool
+	// Default is the value of the field in the documented struct. It is always empty
+	// when Secure is true.
+	Default string
+	// Required is true when the field has no default, i.e. it must be backed by a
+	// remote parameter.
+	Required bool
+}
+
+// Build creates the document out of the node tree and the report rendered from the same
+// tree. Fields not present in the report, e.g. filtered out, are not documented.
+func Build(title string, node *parser.StructNode, rpt *report.Report) *Document {
+	doc := &Document{Title: title}
+	sections := map[string]int{}
+
+	params := map[string][]*report.Parameter{}
+	for i := range rpt.Parameters {
+		prm := &rpt.Parameters[i]
+		params[prm.Name] = append(params[prm.Name], prm)
+	}
+
+	tp := node.Type
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+
+	root := Section{Type: typeName(tp)}
+	doc.Sections = append(doc.Sections, root)
+	sections[""] = 0
+
+	doc.walk(node.Childs, root.Type, params, sections)
+
+	if len(doc.Sections[0].Fields) == 0 && len(doc.Sections) > 1 {
+		doc.Sections = doc.Sections[1:]
+	}
+
+	return doc
+}
+
+func (d *Document) walk(nodes []parser.StructNode, owner string,
+	params map[string][]*report.Parameter, sections map[string]int) {
+
+	for i := range nodes {
+		node := &nodes[i]
+
+		if field, ok := document(node, params); ok {
+			section := ""
+			if idx := strings.LastIndex(node.FqName, "."); idx != -1 {
+				section = node.FqName[:idx]
+			}
+
+			idx, ok := sections[section]
+			if !ok {
+				idx = len(d.Sections)
+				sections[section] = idx
+				d.Sections = append(d.Sections, Section{Name: section, Type: owner})
+			}
+
+			d.Sections[idx].Fields = append(d.Sections[idx].Fields, field)
+			continue
+		}
+
+		if node.HasChildren() {
+			d.walk(node.Childs, typeName(node.Field.Type), params, sections)
+		}
+	}
+}
+
+// document creates the field if any of the node tags has a reported parameter
+func document(node *parser.StructNode, params map[string][]*report.Parameter) (Field, bool) {
+	tags := make([]string, 0, len(node.Tag))
+	for tag := range node.Tag {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		prm := lookup(params[node.Tag[tag].GetFullName()], tag)
+		if prm == nil {
+			continue
+		}
+
+		field := Field{
+			FqName:      node.FqName,
+			Name:        prm.Name,
+			JSONKey:     common.JSONKey(node, tag),
+			Store:       tag,
+			Type:        typeName(node.Field.Type),
+			ValueType:   prm.ValueType,
+			Description: prm.Description,
+			Secure:      prm.Type == report.SecretsManager || prm.ValueType == "SecureString",
+		}
+
+		if details, ok := prm.PmsDetails(); ok && prm.Type == report.ParameterStore {
+			field.Tier = string(details.Tier)
+		}
+
+		if !field.Secure && node.Value.IsValid() && !node.Value.IsZero() {
+			field.Default = common.GetStringValueFromField(node)
+		}
+
+		field.Required = field.Default == ""
+		return field, true
+	}
+
+	return Field{}, false
+}
+
+// lookup finds the parameter reported for the tag. The pms and asm tags must match the
+// parameter type, custom tags accepts any type.
+func lookup(candidates []*report.Parameter, tag string) *report.Parameter {
+	for _, prm := range candidates {
+		switch {
+		case tag == "pms" && prm.Type != report.ParameterStore:
+		case tag == "asm" && prm.Type != report.SecretsManager:
+		default:
+			return prm
+		}
+	}
+
+	return nil
+}
+
+// typeName returns the go type name, or the kind for unnamed types such as
+// anonymous structs.
+func typeName(t reflect.Type) string {
+	if t.Name() == "" && t.Kind() != reflect.Ptr {
+		return t.Kind().String()
+	}
+
+	return t.String()
+}
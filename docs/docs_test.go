@@ -0,0 +1,128 @@
+package docs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+type docsTest struct {
+	Name string `pms:"name, description=The service | display name"`
+	Port int    `pms:"port, tier=adv"`
+	Pwd  string `pms:"pwd, keyid=default"`
+	Db   struct {
+		Host string `pms:"host"`
+		User string `asm:"db, jsonkey=user"`
+		Pwd  string `asm:"db, jsonkey=password"`
+	}
+}
+
+func buildDocs(t *testing.T, v *docsTest, filter *support.FieldFilters) *Document {
+	node, err := parser.New("test-service", "prod", "").
+		RegisterTagParser("pms", pms.NewTagParser()).
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(reflect.ValueOf(v))
+	assert.Equal(t, nil, err)
+
+	rpt, _, err := report.New().RenderReport(node, filter, false)
+	assert.Equal(t, nil, err)
+
+	return Build("Test Service", node, rpt)
+}
+
+func testValue() *docsTest {
+	v := &docsTest{Name: "nisse", Pwd: "s3cr3t"}
+	v.Db.Host = "localhost"
+	v.Db.User = "admin"
+	v.Db.Pwd = "hemligt"
+	return v
+}
+
+func TestBuildGroupsBySubStruct(t *testing.T) {
+	doc := buildDocs(t, testValue(), support.NewFilters())
+
+	assert.Equal(t, 2, len(doc.Sections))
+	assert.Equal(t, "", doc.Sections[0].Name)
+	assert.Equal(t, "docs.docsTest", doc.Sections[0].Type)
+	assert.Equal(t, 3, len(doc.Sections[0].Fields))
+	assert.Equal(t, "Db", doc.Sections[1].Name)
+	assert.Equal(t, 3, len(doc.Sections[1].Fields))
+
+	user := doc.Sections[1].Fields[1]
+	assert.Equal(t, "Db.User", user.FqName)
+	assert.Equal(t, "/prod/test-service/db/db", user.Name)
+	assert.Equal(t, "user", user.JSONKey)
+	assert.Equal(t, "asm", user.Store)
+	assert.Equal(t, true, user.Secure)
+}
+
+func TestBuildDefaultsAndRequired(t *testing.T) {
+	doc := buildDocs(t, testValue(), support.NewFilters())
+	fields := doc.Sections[0].Fields
+
+	assert.Equal(t, "nisse", fields[0].Default)
+	assert.Equal(t, false, fields[0].Required)
+	assert.Equal(t, "The service | display name", fields[0].Description)
+
+	assert.Equal(t, "", fields[1].Default)
+	assert.Equal(t, true, fields[1].Required)
+	assert.Equal(t, "Advanced", fields[1].Tier)
+	assert.Equal(t, "int", fields[1].Type)
+
+	assert.Equal(t, true, fields[2].Secure)
+	assert.Equal(t, "SecureString", fields[2].ValueType)
+	assert.Equal(t, "", fields[2].Default)
+	assert.Equal(t, true, fields[2].Required)
+}
+
+func TestBuildHonorsFilter(t *testing.T) {
+	doc := buildDocs(t, testValue(), support.NewFilters().Exclude("Db"))
+
+	assert.Equal(t, 1, len(doc.Sections))
+	assert.Equal(t, 3, len(doc.Sections[0].Fields))
+}
+
+func TestMarkdownNeverContainsSecureValues(t *testing.T) {
+	doc := buildDocs(t, testValue(), support.NewFilters())
+	md := doc.Markdown()
+
+	assert.Contains(t, md, "# Test Service")
+	assert.Contains(t, md, "## Db (`struct`)")
+	assert.Contains(t, md, "| `Name` | `/prod/test-service/name` | pms | `string` | String | Standard | no | `nisse` | no | The service \\| display name |")
+	assert.Contains(t, md, "`/prod/test-service/db/db` key `user`")
+	assert.Contains(t, md, "`localhost`")
+	assert.NotContains(t, md, "s3cr3t")
+	assert.NotContains(t, md, "admin")
+	assert.NotContains(t, md, "hemligt")
+}
+
+func TestHTMLNeverContainsSecureValues(t *testing.T) {
+	doc := buildDocs(t, testValue(), support.NewFilters())
+	html, err := doc.HTML()
+	assert.Equal(t, nil, err)
+
+	assert.Contains(t, html, "<h1>Test Service</h1>")
+	assert.Contains(t, html, "<td><code>Db.Host</code></td>")
+	assert.Contains(t, html, "<code>localhost</code>")
+	assert.Contains(t, html, "<h2><code>docs.docsTest</code></h2>")
+	assert.NotContains(t, html, "s3cr3t")
+	assert.NotContains(t, html, "admin")
+	assert.NotContains(t, html, "hemligt")
+}
+
+func TestRenderIsStable(t *testing.T) {
+	first := buildDocs(t, testValue(), support.NewFilters())
+	second := buildDocs(t, testValue(), support.NewFilters())
+
+	assert.Equal(t, first.Markdown(), second.Markdown())
+
+	html1, _ := first.HTML()
+	html2, _ := second.HTML()
+	assert.Equal(t, html1, html2)
+}
@@ -0,0 +1,110 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Markdown renders the document as Markdown with one table per section
+func (d *Document) Markdown() string {
+	var sb strings.Builder
+
+	if d.Title != "" {
+		sb.WriteString(fmt.Sprintf("# %s\n\n", cell(d.Title)))
+	}
+
+	for _, s := range d.Sections {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", s.heading()))
+		sb.WriteString("| Field | Name | Store | Type | Value Type | Tier | Secure | Default | Required | Description |\n")
+		sb.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- | --- | --- |\n")
+
+		for _, f := range s.Fields {
+			name := code(f.Name)
+			if f.JSONKey != "" {
+				name += " key " + code(f.JSONKey)
+			}
+
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				code(f.FqName), name, f.Store, code(f.Type), f.ValueType, f.Tier,
+				yesNo(f.Secure), code(f.Default), yesNo(f.Required), cell(f.Description)))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// HTML renders the document as a standalone HTML page with one table per section
+func (d *Document) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, d); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (s Section) heading() string {
+	if s.Name == "" {
+		return code(s.Type)
+	}
+
+	return fmt.Sprintf("%s (%s)", s.Name, code(s.Type))
+}
+
+// cell escapes the text to fit in a single Markdown table cell
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r", "", "\n", "<br>").Replace(s)
+}
+
+// code renders the text as a Markdown code span within a table cell
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	if strings.Contains(s, "`") {
+		return "`` " + cell(s) + " ``"
+	}
+
+	return "`" + cell(s) + "`"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+var htmlTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{"yesno": yesNo}).Parse(
+	`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+{{- if .Title}}
+<h1>{{.Title}}</h1>
+{{- end}}
+{{- range .Sections}}
+<h2>{{if .Name}}{{.Name}} (<code>{{.Type}}</code>){{else}}<code>{{.Type}}</code>{{end}}</h2>
+<table>
+<thead>
+<tr><th>Field</th><th>Name</th><th>Store</th><th>Type</th><th>Value Type</th><th>Tier</th><th>Secure</th><th>Default</th><th>Required</th><th>Description</th></tr>
+</thead>
+<tbody>
+{{- range .Fields}}
+<tr><td><code>{{.FqName}}</code></td><td><code>{{.Name}}</code>{{if .JSONKey}} key <code>{{.JSONKey}}</code>{{end}}</td><td>{{.Store}}</td><td><code>{{.Type}}</code></td><td>{{.ValueType}}</td><td>{{.Tier}}</td><td>{{yesno .Secure}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{yesno .Required}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+</body>
+</html>
+`))
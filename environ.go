@@ -0,0 +1,191 @@
+package ssm
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/pkg/errors"
+)
+
+// EnvNaming converts a full name to an environment variable name. Keys in flattened
+// JSON secrets are appended to the name of the secret using a slash, e.g.
+// /dev/svc/db/password for the password key in the /dev/svc/db secret. If it returns an
+// empty string, the name is not exported.
+type EnvNaming func(name string) string
+
+// TrimPrefixNaming returns the naming that removes the base (e.g. /dev/svc), replaces
+// all characters that are not letters or digits with an underscore and upper cases the
+// rest, e.g. /dev/svc/db/timeout becomes DB_TIMEOUT. Names outside of base use the
+// complete name, e.g. /dev/global/region becomes DEV_GLOBAL_REGION.
+func TrimPrefixNaming(base string) EnvNaming {
+	base = strings.TrimSuffix(base, "/")
+
+	return func(name string) string {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, base+"/"), "/")
+
+		return strings.Map(func(r rune) rune {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return '_'
+			}
+
+			return unicode.ToUpper(r)
+		}, name)
+	}
+}
+
+// EnvOptions controls Environ
+type EnvOptions struct {
+	// Prefixes lists all parameters and secrets whose name starts with any of the prefixes.
+	Prefixes []string
+	// Report is parameters to read, e.g. a report read from file. If both Prefixes and
+	// Report is empty, all parameters under /{env}/{service} are read.
+	Report *report.Report
+	// Naming converts the names. Default is TrimPrefixNaming of /{env}/{service} (or
+	// /{env}/{prefix} when the serializer uses a global prefix).
+	Naming EnvNaming
+	// AllowMissing skips parameters, in the report, that do not exist. By default
+	// Environ fails when any is missing.
+	AllowMissing bool
+}
+
+// Environ reads the live values of the parameters and secrets and returns those as
+// environment variables, on the form KEY=value as os.Environ, sorted by key. Secrets
+// with a JSON object value are flattened into one variable per key. Nested objects
+// are flattened using the key path and other values are exported as JSON, e.g.
+// {"user":"admin","port":5432} in /dev/svc/db becomes DB_USER=admin and DB_PORT=5432.
+// It fails if two names map to the same variable. No value is ever logged.
+func (s *Serializer) Environ(opts EnvOptions) ([]string, error) {
+	base := parser.RenderPrefix(s.prefix, s.env, s.service)
+
+	naming := opts.Naming
+	if naming == nil {
+		naming = TrimPrefixNaming(base)
+	}
+
+	prefixes := opts.Prefixes
+	if len(prefixes) == 0 && opts.Report == nil {
+		prefixes = []string{base + "/"}
+	}
+
+	rpt, err := s.ListReport(prefixes...)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Report != nil {
+		rpt.Parameters = append(rpt.Parameters, opts.Report.Parameters...)
+	}
+
+	if rpt, err = s.DescribeReport(rpt, false); err != nil {
+		return nil, err
+	}
+
+	return environ(rpt, naming, opts.AllowMissing)
+}
+
+// environ renders the environment variables of the live report
+func environ(rpt *report.Report, naming EnvNaming, allowMissing bool) ([]string, error) {
+	vars := map[string]string{}
+	origin := map[string]string{}
+	missing := []string{}
+
+	add := func(name string, value string) error {
+		key := naming(name)
+		if key == "" {
+			return nil
+		}
+
+		if other, ok := origin[key]; ok && other != name {
+			return errors.Errorf("Both %s and %s maps to environment variable %s", other, name, key)
+		}
+
+		vars[key], origin[key] = value, name
+		return nil
+	}
+
+	for _, prm := range rpt.Parameters {
+		if prm.Missing {
+			missing = append(missing, prm.Name)
+			continue
+		}
+
+		if doc, ok := jsonObject(prm); ok {
+			if err := flattenJSON(prm.Name, doc, add); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if err := add(prm.Name, prm.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(missing) > 0 && !allowMissing {
+		return nil, errors.Errorf("Parameters %v do not exist", missing)
+	}
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+
+	return env, nil
+}
+
+// flattenJSON adds each key in the document, where nested objects are appended to the
+// name using a slash.
+func flattenJSON(name string, doc map[string]interface{}, add func(name string, value string) error) error {
+	for key, value := range doc {
+		var err error
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			err = flattenJSON(name+"/"+key, v, add)
+		case string:
+			err = add(name+"/"+key, v)
+		case nil:
+			err = add(name+"/"+key, "")
+		default:
+			data, _ := json.Marshal(v)
+			err = add(name+"/"+key, string(data))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonObject returns the value of a secret when it is a JSON object. Numbers are kept
+// as is, i.e. not converted to float.
+func jsonObject(prm report.Parameter) (map[string]interface{}, bool) {
+	if prm.Type != report.SecretsManager {
+		return nil, false
+	}
+
+	var doc map[string]interface{}
+
+	dec := json.NewDecoder(strings.NewReader(prm.Value))
+	dec.UseNumber()
+
+	if err := dec.Decode(&doc); err != nil || doc == nil {
+		return nil, false
+	}
+
+	return doc, true
+}
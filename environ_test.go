@@ -0,0 +1,77 @@
+package ssm
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimPrefixNaming(t *testing.T) {
+	naming := TrimPrefixNaming("/dev/test-service")
+
+	assert.Equal(t, "DB_TIMEOUT", naming("/dev/test-service/db/timeout"))
+	assert.Equal(t, "DB_CONN_MAX_IDLE", naming("/dev/test-service/db/conn/max-idle"))
+	assert.Equal(t, "DEV_GLOBAL_REGION", naming("/dev/global/region"))
+}
+
+func TestEnvironFlattensJSONSecrets(t *testing.T) {
+	rpt := &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/test-service/timeout", Value: "30"},
+		{Type: report.ParameterStore, Name: "/dev/test-service/settings", Value: `{"batch":1}`},
+		{Type: report.SecretsManager, Name: "/dev/test-service/db",
+			Value: `{"user":"admin","port":5432,"big":12345678901234567890,"ssl":{"mode":"require"},"none":null}`},
+		{Type: report.SecretsManager, Name: "/dev/test-service/token", Value: "s3cr3t"},
+	}}
+
+	env, err := environ(rpt, TrimPrefixNaming("/dev/test-service"), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"DB_BIG=12345678901234567890",
+		"DB_NONE=",
+		"DB_PORT=5432",
+		"DB_SSL_MODE=require",
+		"DB_USER=admin",
+		`SETTINGS={"batch":1}`,
+		"TIMEOUT=30",
+		"TOKEN=s3cr3t",
+	}, env)
+}
+
+func TestEnvironFailsOnCollisionAndMissing(t *testing.T) {
+	naming := TrimPrefixNaming("/dev/test-service")
+
+	_, err := environ(&report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/test-service/db-user", Value: "a"},
+		{Type: report.ParameterStore, Name: "/dev/test-service/db/user", Value: "b"},
+	}}, naming, false)
+	assert.Error(t, err)
+
+	missing := &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/test-service/name", Missing: true},
+		{Type: report.ParameterStore, Name: "/dev/test-service/other", Value: "x"},
+	}}
+
+	_, err = environ(missing, naming, false)
+	assert.Error(t, err)
+
+	env, err := environ(missing, naming, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"OTHER=x"}, env)
+}
+
+func TestEnvironSkipsEmptyNames(t *testing.T) {
+	env, err := environ(&report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/test-service/skip", Value: "a"},
+		{Type: report.ParameterStore, Name: "/dev/test-service/keep", Value: "b"},
+	}}, func(name string) string {
+		if name == "/dev/test-service/skip" {
+			return ""
+		}
+
+		return "KEEP"
+	}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"KEEP=b"}, env)
+}
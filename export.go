@@ -0,0 +1,96 @@
+package ssm
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/mariotoffia/ssm/snapshot"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// Export reads all pms and asm fields of the in param struct and writes those, including
+// the values, as a snapshot to w. All secure values are encrypted using the key, that is
+// either a passphrase or a key generated by snapshot.GenerateKey. Fields that do not
+// exist remotely are not part of the snapshot.
+func (s *Serializer) Export(v interface{}, w io.Writer, key string) error {
+	return s.ExportWithOpts(v, w, key, nil)
+}
+
+// ExportWithOpts is the same as Export but only the fields selected by the filter are
+// exported.
+func (s *Serializer) ExportWithOpts(v interface{}, w io.Writer, key string,
+	filter *support.FieldFilters) error {
+
+	snap, err := s.export(v, filter)
+	if err != nil {
+		return err
+	}
+
+	return snap.Write(w, key)
+}
+
+// Import reads a snapshot written by Export and writes all parameters and secrets
+// using the normal upsert. Secure values are decrypted using the key. If the snapshot
+// was exported from another environment than the serializer, names starting with the
+// snapshot environment are renamed to the serializer environment. It returns the
+// parameters that failed to be written keyed by the remote name.
+func (s *Serializer) Import(r io.Reader, key string) (map[string]support.FullNameField, error) {
+	snap, err := snapshot.Read(r, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.importSnapshot(snap)
+}
+
+func (s *Serializer) export(v interface{},
+	filter *support.FieldFilters) (*snapshot.Snapshot, error) {
+
+	filter = copyFilters(filter)
+
+	invalid, node, err := s.unmarshal(v, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range invalid {
+		filter.Exclude(name)
+	}
+
+	rpt, _, err := report.NewWithTier(s.tier).RenderReport(node, filter, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot.Snapshot{Env: s.env, Service: s.service,
+		Created: time.Now().UTC(), Parameters: rpt.Parameters}, nil
+}
+
+func (s *Serializer) importSnapshot(snap *snapshot.Snapshot) (map[string]support.FullNameField, error) {
+	usage := s.usageOrDefault()
+	params := []report.Parameter{}
+
+	for _, prm := range snap.Parameters {
+		if _, found := find(usage, parameterUsage(&prm)); found {
+			prm.Name = renameEnv(prm.Name, snap.Env, s.env)
+			params = append(params, prm)
+		}
+	}
+
+	return s.PutParameters(params...)
+}
+
+// renameEnv replaces the leading from environment with the to environment in name
+func renameEnv(name string, from string, to string) string {
+	if from == to || from == "" {
+		return name
+	}
+
+	if prefix := "/" + from + "/"; strings.HasPrefix(name, prefix) {
+		return "/" + to + "/" + strings.TrimPrefix(name, prefix)
+	}
+
+	return name
+}
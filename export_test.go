@@ -0,0 +1,91 @@
+package ssm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// parameterStub is a minimal parameter store that keeps the values in memory and
+// records each PutParameter request.
+type parameterStub struct {
+	mu     sync.Mutex
+	values map[string]string
+	puts   []map[string]interface{}
+	srv    *httptest.Server
+}
+
+func newParameterStub(values map[string]string) *parameterStub {
+	stub := &parameterStub{values: values}
+	stub.srv = httptest.NewServer(http.HandlerFunc(stub.handle))
+	return stub
+}
+
+func (stub *parameterStub) config() aws.Config {
+	return aws.Config{Region: "eu-west-1", Credentials: aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(stub.srv.URL)}
+}
+
+func (stub *parameterStub) handle(w http.ResponseWriter, r *http.Request) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var in map[string]interface{}
+	body, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(body, &in)
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "AmazonSSM.GetParameters":
+		params, invalid := []map[string]interface{}{}, []string{}
+		for _, name := range in["Names"].([]interface{}) {
+			if value, ok := stub.values[name.(string)]; ok {
+				params = append(params, map[string]interface{}{"Name": name, "Value": value, "Version": 1})
+			} else {
+				invalid = append(invalid, name.(string))
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"Parameters": params, "InvalidParameters": invalid})
+	case "AmazonSSM.PutParameter":
+		stub.puts = append(stub.puts, in)
+		stub.values[in["Name"].(string)] = in["Value"].(string)
+		json.NewEncoder(w).Encode(map[string]interface{}{"Version": 1})
+	default:
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+type exportTest struct {
+	Name string `pms:"name, keyid=alias/my-key, description=The name, pattern=^[a-z]+$"`
+}
+
+func TestExportImportKeepsKeyDescriptionAndPattern(t *testing.T) {
+	stub := newParameterStub(map[string]string{"/prod/test-service/name": "kalle"})
+	defer stub.srv.Close()
+
+	var buf bytes.Buffer
+	s := NewSsmSerializerFromConfig("prod", "test-service", stub.config())
+	assert.NoError(t, s.Export(&exportTest{}, &buf, "my passphrase"))
+
+	invalid, err := NewSsmSerializerFromConfig("test", "test-service", stub.config()).
+		Import(&buf, "my passphrase")
+
+	assert.NoError(t, err)
+	assert.Empty(t, invalid)
+	assert.Equal(t, 1, len(stub.puts))
+	assert.Equal(t, "/test/test-service/name", stub.puts[0]["Name"])
+	assert.Equal(t, "kalle", stub.puts[0]["Value"])
+	assert.Equal(t, "SecureString", stub.puts[0]["Type"])
+	assert.Equal(t, "alias/my-key", stub.puts[0]["KeyId"])
+	assert.Equal(t, "The name", stub.puts[0]["Description"])
+	assert.Equal(t, "^[a-z]+$", stub.puts[0]["AllowedPattern"])
+}
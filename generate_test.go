@@ -0,0 +1,120 @@
+package ssm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// secretsStub is a minimal secrets manager that keeps the secret strings in memory
+// and records the operations invoked.
+type secretsStub struct {
+	mu         sync.Mutex
+	secrets    map[string]string
+	operations []string
+	srv        *httptest.Server
+}
+
+func newSecretsStub(secrets map[string]string) *secretsStub {
+	stub := &secretsStub{secrets: secrets}
+	stub.srv = httptest.NewServer(http.HandlerFunc(stub.handle))
+	return stub
+}
+
+func (stub *secretsStub) config() aws.Config {
+	return aws.Config{Region: "eu-west-1", Credentials: aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(stub.srv.URL)}
+}
+
+func (stub *secretsStub) handle(w http.ResponseWriter, r *http.Request) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	var in map[string]interface{}
+	body, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(body, &in)
+
+	operation := r.Header.Get("X-Amz-Target")
+	stub.operations = append(stub.operations, operation)
+
+	fail := func(errorType string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"__type": errorType, "message": errorType})
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+	switch operation {
+	case "secretsmanager.GetSecretValue":
+		name := in["SecretId"].(string)
+		if value, ok := stub.secrets[name]; ok {
+			json.NewEncoder(w).Encode(map[string]string{"Name": name, "SecretString": value})
+			return
+		}
+
+		fail("ResourceNotFoundException")
+	case "secretsmanager.GetRandomPassword":
+		json.NewEncoder(w).Encode(map[string]string{"RandomPassword": "generated"})
+	case "secretsmanager.CreateSecret":
+		name := in["Name"].(string)
+		if _, ok := stub.secrets[name]; ok {
+			fail("ResourceExistsException")
+			return
+		}
+
+		stub.secrets[name] = in["SecretString"].(string)
+		json.NewEncoder(w).Encode(map[string]string{"Name": name})
+	case "secretsmanager.UpdateSecret":
+		name := in["SecretId"].(string)
+		stub.secrets[name] = in["SecretString"].(string)
+		json.NewEncoder(w).Encode(map[string]string{"Name": name})
+	default:
+		fail("InvalidRequestException")
+	}
+}
+
+type generateTest struct {
+	Db struct {
+		User     string `json:"user"`
+		Password string `json:"password,omitempty"`
+	} `asm:"db, strkey=password, genexclude=/@"`
+}
+
+func TestMarshalGeneratesStrKeyOnCreate(t *testing.T) {
+	stub := newSecretsStub(map[string]string{})
+	defer stub.srv.Close()
+
+	var test generateTest
+	test.Db.User = "admin"
+
+	s := NewSsmSerializerFromConfig("dev", "my-service", stub.config())
+	assert.Empty(t, s.MarshalWithOpts(&test, NoFilter, OnlyAsm))
+
+	assert.JSONEq(t, `{"user":"admin","password":"generated"}`, stub.secrets["/dev/my-service/db"])
+	assert.Equal(t, []string{"secretsmanager.GetSecretValue", "secretsmanager.GetRandomPassword",
+		"secretsmanager.CreateSecret"}, stub.operations)
+}
+
+func TestMarshalPreservesStrKeyOnUpdate(t *testing.T) {
+	stub := newSecretsStub(map[string]string{
+		"/dev/my-service/db": `{"user":"admin","password":"existing"}`,
+	})
+
+	defer stub.srv.Close()
+
+	var test generateTest
+	test.Db.User = "other"
+
+	s := NewSsmSerializerFromConfig("dev", "my-service", stub.config())
+	assert.Empty(t, s.MarshalWithOpts(&test, NoFilter, OnlyAsm))
+
+	assert.JSONEq(t, `{"user":"other","password":"existing"}`, stub.secrets["/dev/my-service/db"])
+	assert.Equal(t, []string{"secretsmanager.GetSecretValue", "secretsmanager.UpdateSecret"},
+		stub.operations)
+}
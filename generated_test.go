@@ -0,0 +1,34 @@
+package ssm
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+)
+
+// reflectiveStruct has the same fields as testsupport.GeneratedStruct but no generated code
+type reflectiveStruct testsupport.GeneratedStruct
+
+func TestGeneratedReportsAsReflection(t *testing.T) {
+	generated := testsupport.GeneratedStruct{Name: "kalle", Count: 42, Ratio: 0.5,
+		Hosts: []string{"a", "b"}}
+
+	generated.Settings.BatchSize = 77
+	generated.Rds.User = "admin"
+	generated.Sub.Deep.Level = 3
+
+	for _, s := range []*Serializer{
+		NewSsmSerializer("dev", "my-service"),
+		NewSsmSerializer("dev", "my-service").UsePrefix("nested"),
+		NewSsmSerializer("dev", "my-service").UsePrefix("/global"),
+	} {
+		expected, _, err := s.ReportWithOpts((*reflectiveStruct)(&generated), NoFilter, true)
+		assert.NoError(t, err)
+
+		rpt, _, err := s.ReportWithOpts(&generated, NoFilter, true)
+		assert.NoError(t, err)
+
+		assert.Equal(t, expected, rpt)
+	}
+}
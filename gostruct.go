@@ -0,0 +1,29 @@
+package ssm
+
+import (
+	"github.com/mariotoffia/ssm/gostruct"
+	"github.com/mariotoffia/ssm/parser"
+)
+
+// GenerateStructs lists all parameters and secrets under the prefixes, in the stores the
+// serializer uses, and generates go structs with pms and asm tags for those, see
+// gostruct.Generate. If no prefixes are given, everything under /{env}/{service} is
+// listed. The names are rendered relative to the serializer environment, service and
+// prefix, hence the structs are used with a serializer created the same way.
+func (s *Serializer) GenerateStructs(pkg string, name string, prefixes ...string) (*gostruct.Source, error) {
+	if len(prefixes) == 0 {
+		prefixes = []string{parser.RenderPrefix(s.prefix, s.env, s.service) + "/"}
+	}
+
+	rpt, err := s.ListReport(prefixes...)
+	if err != nil {
+		return nil, err
+	}
+
+	if rpt, err = s.DescribeReport(rpt, false); err != nil {
+		return nil, err
+	}
+
+	return gostruct.Generate(rpt, gostruct.Options{Package: pkg, Name: name,
+		Env: s.env, Service: s.service, Prefix: s.prefix})
+}
@@ -0,0 +1,392 @@
+// Package gostruct reverse engineers go structs, with pms and asm tags, out of an
+// existing parameter tree. The types are inferred from the live values, see Generate,
+// and the tags are rendered such that parsing the struct reproduces the remote names.
+package gostruct
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/pkg/errors"
+)
+
+// DefaultName is the root struct name when not set in Options
+const DefaultName = "Config"
+
+// Options controls how the structs are generated
+type Options struct {
+	// Package is the go package name of the generated file. Default is config.
+	Package string
+	// Name is the root struct name. Default is DefaultName.
+	Name string
+	// Env is the environment the serializer will be created with
+	Env string
+	// Service is the service the serializer will be created with
+	Service string
+	// Prefix is the serializer prefix, if any (see Serializer.UsePrefix)
+	Prefix string
+}
+
+// Source is the generated go source
+type Source struct {
+	// Source is the gofmt formatted go source
+	Source []byte
+	// Skipped is the report parameters that could not be generated, keyed by full
+	// name, with the reason as value.
+	Skipped map[string]string
+}
+
+// structType is a generated struct
+type structType struct {
+	name   string
+	fields []*field
+}
+
+// field is a single field in a generated struct
+type field struct {
+	name     string
+	segment  string
+	typ      string
+	tag      string
+	comments []string
+	// child is the struct of a directory, nil for parameters
+	child *structType
+}
+
+type generator struct {
+	opts    Options
+	base    string
+	root    *structType
+	types   []*structType
+	names   map[string]bool
+	skipped map[string]string
+}
+
+// Generate generates a root struct with one field per parameter in the report, which
+// must have the live state (e.g. Serializer.DescribeReport on Serializer.ListReport).
+// Each directory under /{env}/{service} becomes a nested struct and names outside of
+// the service, but in the environment, are rendered using a global prefix.
+//
+// The types are inferred from the values: parameter store StringList is []string,
+// true or false is bool, integers are int and JSON objects become a struct with json
+// tags. Secrets are either a string or a struct. The key, tier (other than standard),
+// description and tags are rendered onto the tag. Values are never rendered.
+//
+// Names that the tags cannot reproduce, e.g. with upper case characters (the tag names
+// are always lower cased) or outside of the environment, are skipped.
+func Generate(rpt *report.Report, opts Options) (*Source, error) {
+	if opts.Package == "" {
+		opts.Package = "config"
+	}
+
+	if opts.Name == "" {
+		opts.Name = DefaultName
+	}
+
+	if opts.Env == "" {
+		return nil, errors.New("Env is required")
+	}
+
+	g := &generator{opts: opts, base: parser.RenderPrefix(opts.Prefix, opts.Env, opts.Service),
+		names: map[string]bool{}, skipped: map[string]string{}}
+
+	g.root = g.newStruct(opts.Name)
+
+	params := make([]report.Parameter, len(rpt.Parameters))
+	copy(params, rpt.Parameters)
+	sort.SliceStable(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+
+	for i := range params {
+		if reason := g.add(&params[i]); reason != "" {
+			g.skipped[params[i].Name] = reason
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+	fmt.Fprintf(&buf, "// Code generated by gostruct out of %s. Review the inferred types.\n\n", g.base)
+
+	for _, st := range g.types {
+		if st == g.root {
+			fmt.Fprintf(&buf, "// %s is the configuration under %s\n", st.name, g.base)
+		}
+
+		fmt.Fprintf(&buf, "type %s struct {\n", st.name)
+
+		for _, f := range st.fields {
+			for _, comment := range f.comments {
+				fmt.Fprintf(&buf, "// %s\n", comment)
+			}
+
+			if f.tag == "" {
+				fmt.Fprintf(&buf, "%s %s\n", f.name, f.typ)
+			} else {
+				fmt.Fprintf(&buf, "%s %s `%s`\n", f.name, f.typ, f.tag)
+			}
+		}
+
+		fmt.Fprintf(&buf, "}\n\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to format generated source")
+	}
+
+	return &Source{Source: src, Skipped: g.skipped}, nil
+}
+
+// add adds the parameter and returns the reason when skipped
+func (g *generator) add(prm *report.Parameter) string {
+	var usage string
+
+	switch prm.Type {
+	case report.ParameterStore:
+		usage = "pms"
+	case report.SecretsManager:
+		usage = "asm"
+	default:
+		return fmt.Sprintf("unsupported parameter type %s", prm.Type)
+	}
+
+	if prm.Missing {
+		return "do not exist"
+	}
+
+	if prm.Name != strings.ToLower(prm.Name) {
+		return "tag names are lower case, the name has upper case characters"
+	}
+
+	if strings.ContainsAny(prm.Name, ",=` \"") {
+		return "the name has characters that cannot be in a tag"
+	}
+
+	envPrefix := "/" + strings.ToLower(strings.Trim(g.opts.Env, "/")) + "/"
+	name := prm.Name[strings.LastIndex(prm.Name, "/")+1:]
+	dir := prm.Name[:strings.LastIndex(prm.Name, "/")]
+
+	if name == "" || !strings.HasPrefix(dir+"/", envPrefix) || dir+"/" == envPrefix {
+		return fmt.Sprintf("the name is not in a directory of the environment %s", envPrefix)
+	}
+
+	owner, nav := g.root, g.base
+	if strings.HasPrefix(dir+"/", g.base+"/") {
+		for _, segment := range splitPath(strings.TrimPrefix(dir, g.base)) {
+			f := g.directory(owner, segment)
+			nav += "/" + strings.ToLower(f.name)
+			owner = f.child
+		}
+	}
+
+	typ, comments, reason := g.inferType(prm, owner, name)
+	if reason != "" {
+		return reason
+	}
+
+	f := &field{name: owner.fieldName(goName(name)), segment: name, typ: typ, comments: comments}
+	f.tag, f.comments = g.renderTag(prm, usage, name, dir, nav, f.comments)
+
+	owner.fields = append(owner.fields, f)
+	return ""
+}
+
+// directory returns the field of the directory segment in owner, creates it if needed
+func (g *generator) directory(owner *structType, segment string) *field {
+	for _, f := range owner.fields {
+		if f.child != nil && f.segment == segment {
+			return f
+		}
+	}
+
+	child := g.newStruct(g.uniqueType(owner.typeName(goName(segment), g.opts.Name)))
+	f := &field{name: owner.fieldName(goName(segment)), segment: segment, typ: child.name, child: child}
+	owner.fields = append(owner.fields, f)
+
+	return f
+}
+
+// renderTag renders the pms or asm tag. A prefix is rendered when the nested struct
+// field names do not reproduce the directory, e.g. db-conn, or when the name is outside
+// of the service. Attributes that cannot be represented in a tag are added as comments.
+func (g *generator) renderTag(prm *report.Parameter, usage string, name string, dir string,
+	nav string, comments []string) (string, []string) {
+
+	attrs := []string{name}
+
+	if dir != nav {
+		attrs = append(attrs, "prefix="+g.relativePrefix(dir))
+	}
+
+	switch {
+	case usage == "pms" && prm.ValueType == string(types.ParameterTypeSecureString):
+		if prm.KeyID == "" || prm.KeyID == "alias/aws/ssm" {
+			attrs = append(attrs, "keyid=default")
+		} else {
+			attrs = append(attrs, "keyid="+prm.KeyID)
+		}
+	case usage == "asm" && prm.KeyID != "" && prm.KeyID != "alias/aws/secretsmanager":
+		attrs = append(attrs, "keyid="+prm.KeyID)
+	}
+
+	if details, ok := prm.PmsDetails(); ok && usage == "pms" {
+		switch details.Tier {
+		case types.ParameterTierAdvanced:
+			attrs = append(attrs, "tier=adv")
+		case types.ParameterTierIntelligentTiering:
+			attrs = append(attrs, "tier=eval")
+		}
+
+		if details.Pattern != "" {
+			if representable(details.Pattern) {
+				attrs = append(attrs, "pattern="+details.Pattern)
+			} else {
+				comments = append(comments, fmt.Sprintf("pattern %q cannot be represented in the tag", details.Pattern))
+			}
+		}
+	}
+
+	if prm.Description != "" {
+		comments = append([]string{prm.Description}, comments...)
+
+		if representable(prm.Description) {
+			attrs = append(attrs, "description="+prm.Description)
+		} else {
+			comments = append(comments, "the description cannot be represented in the tag")
+		}
+	}
+
+	keys := make([]string, 0, len(prm.Tags))
+	for key := range prm.Tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if representable(key) && representable(prm.Tags[key]) && key == strings.ToLower(key) &&
+			!reserved[key] {
+			attrs = append(attrs, key+"="+prm.Tags[key])
+		} else {
+			comments = append(comments, fmt.Sprintf("tag %s=%s cannot be represented in the tag", key, prm.Tags[key]))
+		}
+	}
+
+	return fmt.Sprintf(`%s:"%s"`, usage, strings.Join(attrs, ", ")), comments
+}
+
+// relativePrefix renders the prefix of the directory, relative to the service when
+// inside of it, otherwise global (starting with a slash) in the environment.
+func (g *generator) relativePrefix(dir string) string {
+	env := "/" + strings.ToLower(strings.Trim(g.opts.Env, "/"))
+	svc := env + "/" + strings.ToLower(strings.Trim(g.opts.Service, "/"))
+
+	if g.opts.Service != "" && strings.HasPrefix(dir, svc+"/") {
+		return strings.TrimPrefix(dir, svc+"/")
+	}
+
+	return strings.TrimPrefix(dir, env)
+}
+
+// reserved is the named tag attributes that cannot be used as tag keys
+var reserved = map[string]bool{"name": true, "prefix": true, "keyid": true, "description": true,
+	"pattern": true, "overwrite": true, "tier": true, "jsonkey": true, "version": true,
+	"label": true, "strkey": true, "genlen": true, "genchars": true, "genexclude": true,
+	"versionid": true, "versionstage": true, "rotate": true}
+
+// representable returns true if the value can be a tag value
+func representable(s string) bool {
+	return s != "" && !strings.ContainsAny(s, ",=`\"") && strings.TrimSpace(s) == s
+}
+
+func (g *generator) newStruct(name string) *structType {
+	st := &structType{name: name}
+	g.names[name] = true
+	g.types = append(g.types, st)
+	return st
+}
+
+// fieldName returns a unique field name in the struct
+func (st *structType) fieldName(name string) string {
+	unique := name
+	for i := 2; st.hasField(unique); i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	return unique
+}
+
+func (st *structType) hasField(name string) bool {
+	for _, f := range st.fields {
+		if f.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeName returns the type name of a nested struct in st
+func (st *structType) typeName(name string, root string) string {
+	if st.name == root {
+		return name
+	}
+
+	return st.name + name
+}
+
+// uniqueType returns a type name that is not used
+func (g *generator) uniqueType(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	return unique
+}
+
+func splitPath(path string) []string {
+	segments := []string{}
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
+}
+
+// goName converts a name segment, e.g. db-host, to an exported go name, e.g. DbHost
+func goName(segment string) string {
+	var sb strings.Builder
+
+	upper := true
+	for _, r := range segment {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			if upper {
+				r = []rune(strings.ToUpper(string(r)))[0]
+			}
+
+			sb.WriteRune(r)
+			upper = false
+		case r >= '0' && r <= '9':
+			sb.WriteRune(r)
+			upper = true
+		default:
+			upper = true
+		}
+	}
+
+	name := sb.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F" + name
+	}
+
+	return name
+}
@@ -0,0 +1,183 @@
+package gostruct
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *report.Report {
+	return &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/test-service/name", Value: "kalle", ValueType: "String",
+			Details: report.PmsParameterDetails{Tier: "Standard"}},
+		{Type: report.ParameterStore, Name: "/dev/test-service/db/timeout", Value: "30", ValueType: "String",
+			Description: "timeout in seconds", Tags: map[string]string{"owner": "team"},
+			Details: report.PmsParameterDetails{Tier: "Advanced"}},
+		{Type: report.ParameterStore, Name: "/dev/test-service/db/enabled", Value: "true", ValueType: "String"},
+		{Type: report.ParameterStore, Name: "/dev/test-service/db-conn/hosts", Value: "a,b", ValueType: "StringList"},
+		{Type: report.ParameterStore, Name: "/dev/test-service/db/conn/pwd", Value: "s3cr3t",
+			ValueType: "SecureString", KeyID: "alias/aws/ssm"},
+		{Type: report.ParameterStore, Name: "/dev/test-service/settings", ValueType: "String",
+			Value: `{"batch":77,"signer":"mto","ratio":0.5,"nested":{"on":true},"list":["a","b"]}`},
+		{Type: report.SecretsManager, Name: "/dev/test-service/rds", ValueType: "SecureString",
+			Value: `{"username":"admin","port":5432}`, KeyID: "arn:aws:kms:eu-west-1:000:key/k"},
+		{Type: report.SecretsManager, Name: "/dev/test-service/token", Value: "123", ValueType: "SecureString"},
+		{Type: report.ParameterStore, Name: "/dev/global/region", Value: "eu-west-1", ValueType: "String"},
+		{Type: report.ParameterStore, Name: "/dev/test-service/Upper", Value: "x", ValueType: "String"},
+		{Type: report.ParameterStore, Name: "/prod/test-service/other", Value: "x", ValueType: "String"},
+		{Type: "app-config", Name: "/dev/test-service/cfg"},
+	}}
+}
+
+func TestGenerateInfersTypesAndTags(t *testing.T) {
+	src, err := Generate(testReport(), Options{Env: "dev", Service: "test-service"})
+	assert.NoError(t, err)
+
+	code := squash(string(src.Source))
+
+	assert.Contains(t, code, "package config")
+	assert.Contains(t, code, "type Config struct {")
+	assert.Contains(t, code, "Name string `pms:\"name\"`")
+	assert.Contains(t, code, "Timeout int `pms:\"timeout, tier=adv, description=timeout in seconds, owner=team\"`")
+	assert.Contains(t, code, "// timeout in seconds")
+	assert.Contains(t, code, "Enabled bool `pms:\"enabled\"`")
+	assert.Contains(t, code, "Hosts []string `pms:\"hosts, prefix=db-conn\"`")
+	assert.Contains(t, code, "Pwd string `pms:\"pwd, keyid=default\"`")
+	assert.Contains(t, code, "Settings Settings `pms:\"settings\"`")
+	assert.Contains(t, code, "Batch int `json:\"batch\"`")
+	assert.Contains(t, code, "Ratio float64 `json:\"ratio\"`")
+	assert.Contains(t, code, "List []string `json:\"list\"`")
+	assert.Contains(t, code, "Nested SettingsNested `json:\"nested\"`")
+	assert.Contains(t, code, "Rds Rds `asm:\"rds, keyid=arn:aws:kms:eu-west-1:000:key/k\"`")
+	assert.Contains(t, code, "Token string `asm:\"token\"`")
+	assert.Contains(t, code, "Region string `pms:\"region, prefix=/global\"`")
+	assert.NotContains(t, code, "s3cr3t")
+
+	assert.Equal(t, 3, len(src.Skipped))
+	assert.Contains(t, src.Skipped, "/dev/test-service/Upper")
+	assert.Contains(t, src.Skipped, "/prod/test-service/other")
+	assert.Contains(t, src.Skipped, "/dev/test-service/cfg")
+}
+
+func TestGenerateReproducesNames(t *testing.T) {
+	for _, opts := range []Options{
+		{Env: "dev", Service: "test-service"},
+		{Env: "dev", Service: "test-service", Prefix: "/global"},
+	} {
+		src, err := Generate(testReport(), opts)
+		assert.NoError(t, err)
+
+		expected := []string{}
+		for _, prm := range testReport().Parameters {
+			if _, skipped := src.Skipped[prm.Name]; !skipped {
+				expected = append(expected, prm.Name)
+			}
+		}
+
+		sort.Strings(expected)
+		assert.Equal(t, expected, parsedNames(t, src.Source, opts), string(src.Source))
+	}
+}
+
+func TestGenerateUniqueFieldNames(t *testing.T) {
+	src, err := Generate(&report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/svc/db", Value: "x"},
+		{Type: report.ParameterStore, Name: "/dev/svc/db/host", Value: "y"},
+		{Type: report.ParameterStore, Name: "/dev/svc/d-b", Value: "z"},
+	}}, Options{Env: "dev", Service: "svc"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/dev/svc/d-b", "/dev/svc/db", "/dev/svc/db/host"},
+		parsedNames(t, src.Source, Options{Env: "dev", Service: "svc"}), string(src.Source))
+}
+
+// squash replaces all spaces and tabs with a single space
+func squash(code string) string {
+	return regexp.MustCompile(`[ \t]+`).ReplaceAllString(code, " ")
+}
+
+// parsedNames builds the root struct of the generated source using reflection and
+// returns the sorted full names the pms and asm tag parsers render.
+func parsedNames(t *testing.T, src []byte, opts Options) []string {
+	file, err := goparser.ParseFile(token.NewFileSet(), "config.go", src, 0)
+	assert.NoError(t, err)
+
+	specs := map[string]*ast.StructType{}
+	for _, decl := range file.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok {
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				specs[ts.Name.Name] = ts.Type.(*ast.StructType)
+			}
+		}
+	}
+
+	var typeOf func(expr ast.Expr) reflect.Type
+	typeOf = func(expr ast.Expr) reflect.Type {
+		switch e := expr.(type) {
+		case *ast.ArrayType:
+			return reflect.SliceOf(typeOf(e.Elt))
+		case *ast.InterfaceType:
+			return reflect.TypeOf((*interface{})(nil)).Elem()
+		case *ast.Ident:
+			switch e.Name {
+			case "string":
+				return reflect.TypeOf("")
+			case "int":
+				return reflect.TypeOf(0)
+			case "int64":
+				return reflect.TypeOf(int64(0))
+			case "float64":
+				return reflect.TypeOf(float64(0))
+			case "bool":
+				return reflect.TypeOf(false)
+			}
+
+			fields := []reflect.StructField{}
+			for _, f := range specs[e.Name].Fields.List {
+				sf := reflect.StructField{Name: f.Names[0].Name, Type: typeOf(f.Type)}
+				if f.Tag != nil {
+					tag, _ := strconv.Unquote(f.Tag.Value)
+					sf.Tag = reflect.StructTag(tag)
+				}
+
+				fields = append(fields, sf)
+			}
+
+			return reflect.StructOf(fields)
+		}
+
+		t.Fatalf("unexpected type %T", expr)
+		return nil
+	}
+
+	node, err := parser.New(opts.Service, opts.Env, opts.Prefix).
+		RegisterTagParser("pms", pms.NewTagParser()).
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(reflect.New(typeOf(ast.NewIdent(DefaultName))))
+
+	assert.NoError(t, err)
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, support.NewFilters(), []string{"pms", "asm"})
+
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,140 @@
+package gostruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm/report"
+)
+
+// inferType returns the go type of the parameter value. JSON objects are generated as
+// a struct named after the owner and name. It returns the reason if not supported.
+func (g *generator) inferType(prm *report.Parameter, owner *structType, name string) (string, []string, string) {
+	if prm.Type == report.ParameterStore && prm.ValueType == string(types.ParameterTypeStringList) {
+		return "[]string", nil, ""
+	}
+
+	if doc, ok := jsonObject(prm.Value); ok {
+		typ, err := g.objectType(owner.typeName(goName(name), g.opts.Name), doc)
+		if err != nil {
+			return "", nil, err.Error()
+		}
+
+		return typ, nil, ""
+	}
+
+	if prm.Type == report.SecretsManager {
+		return "string", nil, ""
+	}
+
+	return scalarType(prm.Value), nil, ""
+}
+
+// scalarType infers bool and int, otherwise string
+func scalarType(value string) string {
+	if value == "true" || value == "false" {
+		return "bool"
+	}
+
+	if i, err := strconv.Atoi(value); err == nil && strconv.Itoa(i) == value {
+		return "int"
+	}
+
+	return "string"
+}
+
+// objectType generates a struct, with json tags, of the JSON object and returns its name
+func (g *generator) objectType(name string, doc map[string]interface{}) (string, error) {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		if !representable(key) {
+			return "", fmt.Errorf("the JSON key %q cannot be a json tag", key)
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	st := g.newStruct(g.uniqueType(name))
+
+	for _, key := range keys {
+		typ, err := g.jsonType(st.name+goName(key), doc[key])
+		if err != nil {
+			return "", err
+		}
+
+		st.fields = append(st.fields, &field{name: st.fieldName(goName(key)), segment: key, typ: typ,
+			tag: fmt.Sprintf(`json:"%s"`, key)})
+	}
+
+	return st.name, nil
+}
+
+// jsonType returns the go type of a JSON value
+func (g *generator) jsonType(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case bool:
+		return "bool", nil
+	case string:
+		return "string", nil
+	case json.Number:
+		return numberType(v), nil
+	case map[string]interface{}:
+		return g.objectType(name, v)
+	case []interface{}:
+		elem := ""
+		for _, item := range v {
+			typ, err := g.jsonType(name+"Item", item)
+			if err != nil {
+				return "", err
+			}
+
+			if elem != "" && elem != typ {
+				return "[]interface{}", nil
+			}
+
+			elem = typ
+		}
+
+		if elem == "" {
+			return "[]interface{}", nil
+		}
+
+		return "[]" + elem, nil
+	}
+
+	return "interface{}", nil
+}
+
+// numberType returns int when the number is an integer that fits, otherwise float64
+func numberType(n json.Number) string {
+	if i, err := n.Int64(); err == nil && !strings.ContainsAny(n.String(), ".eE") {
+		if i >= math.MinInt32 && i <= math.MaxInt32 {
+			return "int"
+		}
+
+		return "int64"
+	}
+
+	return "float64"
+}
+
+// jsonObject returns the value when it is a JSON object
+func jsonObject(value string) (map[string]interface{}, bool) {
+	var doc map[string]interface{}
+
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+
+	if err := dec.Decode(&doc); err != nil || doc == nil {
+		return nil, false
+	}
+
+	return doc, true
+}
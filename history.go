@@ -0,0 +1,76 @@
+package ssm
+
+import (
+	"reflect"
+
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// parsePms parses the in param struct with only the pms tag parser registered
+func (s *Serializer) parsePms(v interface{}) (*parser.StructNode, error) {
+	return parser.New(s.service, s.env, s.prefix).
+		RegisterTagParser("pms", pms.NewTagParser()).
+		Parse(reflect.ValueOf(v))
+}
+
+func (s *Serializer) history(v interface{},
+	filter *support.FieldFilters) (map[string][]support.ParameterVersion, error) {
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	node, err := s.parsePms(v)
+	if err != nil {
+		return nil, err
+	}
+
+	pmsRepository, err := s.getAndConfigurePms()
+	if err != nil {
+		return nil, err
+	}
+
+	return pmsRepository.History(node, filter)
+}
+
+func (s *Serializer) labelVersions(v interface{},
+	filter *support.FieldFilters, label string) (map[string]support.FullNameField, error) {
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	node, err := s.parsePms(v)
+	if err != nil {
+		return nil, err
+	}
+
+	pmsRepository, err := s.getAndConfigurePms()
+	if err != nil {
+		return nil, err
+	}
+
+	return pmsRepository.LabelVersions(node, filter, label), nil
+}
+
+func (s *Serializer) rollback(v interface{},
+	filter *support.FieldFilters, label string) (map[string]support.FullNameField, error) {
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	node, err := s.parsePms(v)
+	if err != nil {
+		return nil, err
+	}
+
+	pmsRepository, err := s.getAndConfigurePms()
+	if err != nil {
+		return nil, err
+	}
+
+	return pmsRepository.Rollback(node, filter, label), nil
+}
@@ -0,0 +1,292 @@
+// Package iam generates least-privilege IAM policy documents out of a parsed node
+// tree. Only the actions needed by the chosen operations are granted and only on the
+// parameters, secrets and KMS keys that the tree references.
+package iam
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+// Operation is a serializer operation to grant access to
+type Operation string
+
+const (
+	// Unmarshal grants reading parameters and secrets
+	Unmarshal Operation = "unmarshal"
+	// Marshal grants writing (upsert) parameters and secrets
+	Marshal Operation = "marshal"
+	// Delete grants deleting parameters and secrets
+	Delete Operation = "delete"
+	// Watch grants periodic polling of values and metadata to detect changes
+	Watch Operation = "watch"
+)
+
+// PolicyVersion is the IAM policy language version
+const PolicyVersion = "2012-10-17"
+
+// Document is a IAM policy document
+type Document struct {
+	Version   string      `json:"Version"`
+	Statement []Statement `json:"Statement"`
+}
+
+// Statement is a single statement in the policy document
+type Statement struct {
+	Sid       string                         `json:"Sid,omitempty"`
+	Effect    string                         `json:"Effect"`
+	Action    []string                       `json:"Action"`
+	Resource  []string                       `json:"Resource"`
+	Condition map[string]map[string][]string `json:"Condition,omitempty"`
+}
+
+// Options specifies how the ARNs are rendered
+type Options struct {
+	// Partition is the AWS partition, default is aws
+	Partition string
+	// Region is the region, default is *
+	Region string
+	// Account is the account id, default is *
+	Account string
+	// Filter selects the fields to grant access to. If nil, all fields are included.
+	Filter *support.FieldFilters
+}
+
+// CloudFormationOptions returns options that renders ARNs using the CloudFormation
+// pseudo parameters, e.g. ${AWS::Region}. Use those when embedding the document in a
+// CloudFormation template where the ARNs are substituted.
+func CloudFormationOptions() Options {
+	return Options{Partition: "${AWS::Partition}", Region: "${AWS::Region}", Account: "${AWS::AccountId}"}
+}
+
+// JSON renders the document as indented JSON
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Policy walks the node tree and creates a policy document that grants the operations
+// on the pms parameters and asm secrets in the tree. Secrets are matched using the six
+// character suffix that secrets manager appends (-??????). KMS actions are granted on
+// the keys in use (the account default key needs no grant) and tagging actions only
+// on parameters and secrets that do have tags.
+func Policy(node *parser.StructNode, opts Options, ops ...Operation) (*Document, error) {
+	if opts.Partition == "" {
+		opts.Partition = "aws"
+	}
+	if opts.Region == "" {
+		opts.Region = "*"
+	}
+	if opts.Account == "" {
+		opts.Account = "*"
+	}
+	if opts.Filter == nil {
+		opts.Filter = support.NewFilters()
+	}
+
+	g := &grants{opts: opts, actions: map[string]map[string]bool{}, aliases: map[string]map[string]bool{}}
+
+	for _, op := range ops {
+		switch op {
+		case Unmarshal, Marshal, Delete, Watch:
+		default:
+			return nil, errors.Errorf("Unknown operation %s", op)
+		}
+
+		g.pms(node, op)
+		g.asm(node, op)
+	}
+
+	return g.document(), nil
+}
+
+// grants collects the actions keyed by resource ARN
+type grants struct {
+	opts    Options
+	actions map[string]map[string]bool
+	// aliases is the KMS actions keyed by alias name (without alias/)
+	aliases map[string]map[string]bool
+}
+
+func (g *grants) grant(resource string, actions ...string) {
+	set, ok := g.actions[resource]
+	if !ok {
+		set = map[string]bool{}
+		g.actions[resource] = set
+	}
+
+	for _, action := range actions {
+		set[action] = true
+	}
+}
+
+// grantKey grants the KMS actions on the key unless it is the account default key or
+// a local key that is not resolved.
+func (g *grants) grantKey(keyid string, actions ...string) {
+	switch {
+	case keyid == "" || keyid == "default" || strings.HasPrefix(keyid, "local://"):
+		return
+	case strings.HasPrefix(keyid, "arn:"):
+		g.grant(keyid, actions...)
+	case strings.HasPrefix(keyid, "alias/"):
+		alias := strings.TrimPrefix(keyid, "alias/")
+		if g.aliases[alias] == nil {
+			g.aliases[alias] = map[string]bool{}
+		}
+
+		for _, action := range actions {
+			g.aliases[alias][action] = true
+		}
+	default:
+		g.grant(g.arn("kms", "key/"+keyid), actions...)
+	}
+}
+
+func (g *grants) arn(service string, resource string) string {
+	return fmt.Sprintf("arn:%s:%s:%s:%s:%s", g.opts.Partition, service, g.opts.Region, g.opts.Account, resource)
+}
+
+func (g *grants) pms(node *parser.StructNode, op Operation) {
+	groups := map[string][]*parser.StructNode{}
+	parser.NodesToParameterGroups(node, groups, g.opts.Filter, []string{"pms"})
+
+	for name, group := range groups {
+		tag, _ := pms.ToPmsTag(group[0])
+		arn := g.arn("ssm", "parameter"+name)
+
+		switch op {
+		case Unmarshal:
+			g.grant(arn, "ssm:GetParameters")
+			g.grantKey(tag.GetKeyName(), "kms:Decrypt")
+		case Marshal:
+			g.grant(arn, "ssm:PutParameter")
+			g.grantKey(tag.GetKeyName(), "kms:Encrypt", "kms:GenerateDataKey")
+
+			if common.IsJSONKeyGroup(group, "pms") {
+				g.grant(arn, "ssm:GetParameter")
+				g.grantKey(tag.GetKeyName(), "kms:Decrypt")
+			}
+
+			if len(tag.GetTags()) > 0 {
+				g.grant(arn, "ssm:AddTagsToResource")
+			}
+		case Delete:
+			g.grant(arn, "ssm:DeleteParameters")
+		case Watch:
+			g.grant(arn, "ssm:GetParameters")
+			g.grant("*", "ssm:DescribeParameters")
+			g.grantKey(tag.GetKeyName(), "kms:Decrypt")
+		}
+	}
+}
+
+func (g *grants) asm(node *parser.StructNode, op Operation) {
+	groups := map[string][]*parser.StructNode{}
+	parser.NodesToParameterGroups(node, groups, g.opts.Filter, []string{"asm"})
+
+	for name, group := range groups {
+		tag, _ := asm.ToAsmTag(group[0])
+		arn := g.arn("secretsmanager", "secret:"+name+"-??????")
+
+		switch op {
+		case Unmarshal:
+			g.grant(arn, "secretsmanager:GetSecretValue", "secretsmanager:DescribeSecret")
+			g.grantKey(tag.GetKeyName(), "kms:Decrypt")
+
+			if tag.VersionID() == "" && tag.VersionStage() == "" {
+				g.grant("*", "secretsmanager:BatchGetSecretValue")
+			}
+		case Marshal:
+			g.grant(arn, "secretsmanager:CreateSecret", "secretsmanager:UpdateSecret")
+			g.grantKey(tag.GetKeyName(), "kms:Encrypt", "kms:GenerateDataKey")
+
+			policy, _ := tag.GeneratePolicy()
+			if common.IsJSONKeyGroup(group, "asm") || policy != nil {
+				g.grant(arn, "secretsmanager:GetSecretValue")
+				g.grantKey(tag.GetKeyName(), "kms:Decrypt")
+			}
+
+			if policy != nil && policy.Chars == "" {
+				g.grant("*", "secretsmanager:GetRandomPassword")
+			}
+
+			if len(tag.GetTags()) > 0 {
+				g.grant(arn, "secretsmanager:TagResource")
+			}
+		case Delete:
+			g.grant(arn, "secretsmanager:DeleteSecret")
+		case Watch:
+			g.grant(arn, "secretsmanager:GetSecretValue", "secretsmanager:DescribeSecret")
+			g.grantKey(tag.GetKeyName(), "kms:Decrypt")
+		}
+	}
+}
+
+// document groups all resources with the same set of actions into a statement
+func (g *grants) document() *Document {
+	byActions := map[string][]string{}
+	for resource, set := range g.actions {
+		key := strings.Join(sortedSet(set), ",")
+		byActions[key] = append(byActions[key], resource)
+	}
+
+	keys := make([]string, 0, len(byActions))
+	for key := range byActions {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	doc := &Document{Version: PolicyVersion, Statement: []Statement{}}
+
+	for i, key := range keys {
+		resources := byActions[key]
+		sort.Strings(resources)
+
+		doc.Statement = append(doc.Statement, Statement{
+			Sid:      fmt.Sprintf("Ssm%d", i),
+			Effect:   "Allow",
+			Action:   strings.Split(key, ","),
+			Resource: resources,
+		})
+	}
+
+	aliases := make([]string, 0, len(g.aliases))
+	for alias := range g.aliases {
+		aliases = append(aliases, alias)
+	}
+
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		doc.Statement = append(doc.Statement, Statement{
+			Sid:      fmt.Sprintf("Ssm%d", len(doc.Statement)),
+			Effect:   "Allow",
+			Action:   sortedSet(g.aliases[alias]),
+			Resource: []string{g.arn("kms", "key/*")},
+			Condition: map[string]map[string][]string{
+				"ForAnyValue:StringEquals": {"kms:ResourceAliases": {"alias/" + alias}},
+			},
+		})
+	}
+
+	return doc
+}
+
+func sortedSet(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for value := range set {
+		list = append(list, value)
+	}
+
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,107 @@
+package iam
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+type policyTest struct {
+	Name    string `pms:"name"`
+	Tagged  string `pms:"tagged, owner=team"`
+	Secure  string `pms:"secure, keyid=arn:aws:kms:eu-west-1:123:key/abc"`
+	Aliased string `pms:"aliased, keyid=alias/mykey"`
+	Db      string `asm:"db"`
+	Prev    string `asm:"prev, vs=AWSPREVIOUS"`
+	Gen     struct {
+		Password string `json:"password"`
+	} `asm:"gen, strkey=password, genlen=16"`
+}
+
+func parsePolicyTest(t *testing.T) *parser.StructNode {
+	node, err := parser.New("test-service", "prod", "").
+		RegisterTagParser("pms", pms.NewTagParser()).
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(reflect.ValueOf(&policyTest{}))
+
+	assert.Equal(t, nil, err)
+	return node
+}
+
+func statementFor(doc *Document, resource string) *Statement {
+	for i := range doc.Statement {
+		for _, r := range doc.Statement[i].Resource {
+			if r == resource {
+				return &doc.Statement[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestPolicyUnmarshal(t *testing.T) {
+	doc, err := Policy(parsePolicyTest(t), Options{Region: "eu-west-1", Account: "123"}, Unmarshal)
+	assert.Equal(t, nil, err)
+
+	st := statementFor(doc, "arn:aws:ssm:eu-west-1:123:parameter/prod/test-service/name")
+	assert.Equal(t, []string{"ssm:GetParameters"}, st.Action)
+
+	st = statementFor(doc, "arn:aws:secretsmanager:eu-west-1:123:secret:/prod/test-service/db-??????")
+	assert.Equal(t, []string{"secretsmanager:DescribeSecret", "secretsmanager:GetSecretValue"}, st.Action)
+
+	st = statementFor(doc, "*")
+	assert.Equal(t, []string{"secretsmanager:BatchGetSecretValue"}, st.Action)
+
+	st = statementFor(doc, "arn:aws:kms:eu-west-1:123:key/abc")
+	assert.Equal(t, []string{"kms:Decrypt"}, st.Action)
+
+	st = statementFor(doc, "arn:aws:kms:eu-west-1:123:key/*")
+	assert.Equal(t, []string{"alias/mykey"}, st.Condition["ForAnyValue:StringEquals"]["kms:ResourceAliases"])
+}
+
+func TestPolicyMarshalGrantsTaggingOnlyWhenTags(t *testing.T) {
+	doc, err := Policy(parsePolicyTest(t), Options{}, Marshal)
+	assert.Equal(t, nil, err)
+
+	st := statementFor(doc, "arn:aws:ssm:*:*:parameter/prod/test-service/tagged")
+	assert.Equal(t, []string{"ssm:AddTagsToResource", "ssm:PutParameter"}, st.Action)
+
+	st = statementFor(doc, "arn:aws:ssm:*:*:parameter/prod/test-service/name")
+	assert.Equal(t, []string{"ssm:PutParameter"}, st.Action)
+
+	st = statementFor(doc, "arn:aws:secretsmanager:*:*:secret:/prod/test-service/gen-??????")
+	assert.Equal(t, []string{"secretsmanager:CreateSecret", "secretsmanager:GetSecretValue",
+		"secretsmanager:UpdateSecret"}, st.Action)
+
+	st = statementFor(doc, "*")
+	assert.Equal(t, []string{"secretsmanager:GetRandomPassword"}, st.Action)
+}
+
+func TestPolicyDeleteAndUnknownOperation(t *testing.T) {
+	doc, err := Policy(parsePolicyTest(t), Options{}, Delete)
+	assert.Equal(t, nil, err)
+
+	st := statementFor(doc, "arn:aws:secretsmanager:*:*:secret:/prod/test-service/prev-??????")
+	assert.Equal(t, []string{"secretsmanager:DeleteSecret"}, st.Action)
+	assert.Nil(t, statementFor(doc, "arn:aws:kms:*:*:key/abc"))
+
+	_, err = Policy(parsePolicyTest(t), Options{}, Operation("rotate"))
+	assert.Error(t, err)
+}
+
+func TestPolicyIsDeterministic(t *testing.T) {
+	first, _ := Policy(parsePolicyTest(t), CloudFormationOptions(), Unmarshal, Marshal, Watch)
+	second, _ := Policy(parsePolicyTest(t), CloudFormationOptions(), Unmarshal, Marshal, Watch)
+
+	a, err := first.JSON()
+	assert.Equal(t, nil, err)
+
+	b, _ := second.JSON()
+	assert.Equal(t, string(a), string(b))
+	assert.Contains(t, string(a), "arn:${AWS::Partition}:ssm:${AWS::Region}:${AWS::AccountId}:parameter/prod/test-service/name")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/mariotoffia/ssm/internal/common"
 	"github.com/mariotoffia/ssm/parser"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
@@ -65,9 +66,7 @@ func (p *Serializer) getFromAws(prm string,
 		params = &secretsmanager.GetSecretValueInput{SecretId: aws.String(prm), VersionId: aws.String(nasm.VersionID())}
 	}
 
-	client := secretsmanager.NewFromConfig(p.config)
-
-	resp, err := client.GetSecretValue(context.Background(), params)
+	resp, err := p.client.GetSecretValue(context.Background(), params)
 
 	if err != nil {
 		log.Debug().Msgf("error for '%s': %v err %v", prm, resp, err)
@@ -135,3 +134,28 @@ func (p *Serializer) tagAwsSecret(client *secretsmanager.Client,
 	return resp, nil
 
 }
+
+// mergeJSONKeys fetches the current secret (if any) and merges the jsonkey
+// fields onto that document so sibling keys are not clobbered.
+func (p *Serializer) mergeJSONKeys(client *secretsmanager.Client,
+	name string, nodes []*parser.StructNode) (string, error) {
+
+	document := ""
+	resp, err := client.GetSecretValue(context.Background(),
+		&secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+
+	if err != nil {
+
+		var resourceNotFound *types.ResourceNotFoundException
+		if !errors.As(err, &resourceNotFound) {
+			return "", err
+		}
+
+	} else if resp.SecretString != nil {
+
+		document = *resp.SecretString
+
+	}
+
+	return common.MergeJSONKeys(document, nodes, "asm")
+}
@@ -0,0 +1,248 @@
+package asm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// maxBatchSize is the max number of secret ids that BatchGetSecretValue accepts
+const maxBatchSize = 20
+
+// fetched is the outcome of fetch
+type fetched struct {
+	// found is the found secrets by name
+	found map[string]*secretsmanager.GetSecretValueOutput
+	// missing is the names of the secrets that do not exist
+	missing []string
+	// deleted is the names of the secrets that are scheduled for deletion
+	deleted []string
+}
+
+func (f *fetched) merge(other *fetched) {
+	for name, result := range other.found {
+		f.found[name] = result
+	}
+
+	f.missing = append(f.missing, other.missing...)
+	f.deleted = append(f.deleted, other.deleted...)
+}
+
+func newFetched() *fetched {
+	return &fetched{found: map[string]*secretsmanager.GetSecretValueOutput{},
+		missing: []string{}, deleted: []string{}}
+}
+
+// fetch gets all secrets in prms. Secrets without vid or vs is fetched in batches
+// using BatchGetSecretValue, the rest is fetched in parallel (bounded by the serializer
+// concurrency) using GetSecretValue. It returns the found secrets by name and the names
+// of those secrets that do not exist or are scheduled for deletion.
+func (p *Serializer) fetch(prms []string,
+	m map[string]*parser.StructNode) (*fetched, error) {
+
+	batched := []string{}
+	versioned := []string{}
+
+	for _, prm := range prms {
+		if nasm, ok := ToAsmTag(m[prm]); ok {
+
+			if nasm.VersionID() == "" && nasm.VersionStage() == "" {
+				batched = append(batched, prm)
+			} else {
+				versioned = append(versioned, prm)
+			}
+
+		} else {
+
+			log.Warn().Str("svc", p.service).Msgf("tag is not asm tag! tag: %v", m[prm])
+
+		}
+	}
+
+	result := newFetched()
+
+	for i := 0; i < len(batched); i += maxBatchSize {
+		end := i + maxBatchSize
+		if end > len(batched) {
+			end = len(batched)
+		}
+
+		batch, err := p.batchGetFromAws(batched[i:end])
+		if err != nil {
+
+			// E.g. not allowed to do batch get - fallback on single get
+			log.Debug().Str("svc", p.service).Str("method", "fetch").
+				Msgf("batch get failed, falling back on single get error: %v", err)
+
+			versioned = append(versioned, batched[i:end]...)
+			continue
+
+		}
+
+		result.merge(batch)
+	}
+
+	if len(versioned) == 0 {
+		return result, nil
+	}
+
+	single, err := p.parallelGetFromAws(versioned, m)
+	if err != nil {
+		return nil, err
+	}
+
+	result.merge(single)
+	return result, nil
+}
+
+// isScheduledForDeletion checks if the secret exists but is deleted with a
+// recovery window and hence may be restored.
+func (p *Serializer) isScheduledForDeletion(name string) bool {
+	resp, err := p.client.DescribeSecret(context.Background(),
+		&secretsmanager.DescribeSecretInput{SecretId: aws.String(name)})
+
+	if err != nil {
+		return false
+	}
+
+	return resp.DeletedDate != nil
+}
+
+// batchGetFromAws fetches a set of secrets (max 20) using a single BatchGetSecretValue
+// operation (paginated if needed).
+func (p *Serializer) batchGetFromAws(names []string) (*fetched, error) {
+
+	result := newFetched()
+	input := &secretsmanager.BatchGetSecretValueInput{SecretIdList: names}
+
+	for {
+
+		resp, err := p.client.BatchGetSecretValue(context.Background(), input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range resp.Errors {
+
+			name := aws.ToString(e.SecretId)
+
+			switch aws.ToString(e.ErrorCode) {
+			case "ResourceNotFoundException":
+				result.missing = append(result.missing, name)
+				continue
+			case "InvalidRequestException":
+				if p.isScheduledForDeletion(name) {
+					result.deleted = append(result.deleted, name)
+					continue
+				}
+			}
+
+			return nil, errors.Errorf("Failed fetch asm config entry %s (%s): %s",
+				name, aws.ToString(e.ErrorCode), aws.ToString(e.Message))
+
+		}
+
+		for i := range resp.SecretValues {
+
+			v := resp.SecretValues[i]
+			result.found[aws.ToString(v.Name)] = &secretsmanager.GetSecretValueOutput{
+				ARN:           v.ARN,
+				CreatedDate:   v.CreatedDate,
+				Name:          v.Name,
+				SecretBinary:  v.SecretBinary,
+				SecretString:  v.SecretString,
+				VersionId:     v.VersionId,
+				VersionStages: v.VersionStages,
+			}
+
+		}
+
+		if resp.NextToken == nil {
+			break
+		}
+
+		input.NextToken = resp.NextToken
+	}
+
+	return result, nil
+}
+
+// parallelGetFromAws fetches each secret using GetSecretValue with at most the
+// serializer concurrency number of requests in flight.
+func (p *Serializer) parallelGetFromAws(names []string,
+	m map[string]*parser.StructNode) (*fetched, error) {
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+
+	result := newFetched()
+
+	work := make(chan string)
+	workers := p.concurrency
+	if workers > len(names) {
+		workers = len(names)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for prm := range work {
+
+				nasm, _ := ToAsmTag(m[prm])
+				if nasm == nil {
+					nasm = &AsmTagStruct{}
+				}
+
+				resp, err := p.getFromAws(prm, nasm)
+
+				var resourceNotFound *types.ResourceNotFoundException
+				var invalidRequest *types.InvalidRequestException
+
+				deleted := err != nil && errors.As(err, &invalidRequest) && p.isScheduledForDeletion(prm)
+
+				mu.Lock()
+
+				if deleted {
+					result.deleted = append(result.deleted, prm)
+				} else if err != nil {
+
+					if errors.As(err, &resourceNotFound) {
+						result.missing = append(result.missing, prm)
+					} else if firstErr == nil {
+						firstErr = errors.Wrapf(err, "Failed fetch asm config entry %s", prm)
+					}
+
+				} else {
+
+					result.found[prm] = resp
+
+				}
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, prm := range names {
+		work <- prm
+	}
+
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return result, nil
+}
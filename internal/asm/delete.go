@@ -2,8 +2,6 @@ package asm
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,16 +9,21 @@ import (
 	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/mariotoffia/ssm/parser"
 	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
 // Delete will delete the paths found in nodes.
+//
+// If a recovery window is set on the tag (recovery) or on the serializer, the
+// secret is scheduled for deletion and may be restored using Restore within the
+// window. Otherwise it is deleted without any recovery.
 func (p *Serializer) Delete(
 	node *parser.StructNode,
 	filter *support.FieldFilters) (map[string]support.FullNameField, error) {
 
 	m := map[string]*parser.StructNode{}
-	svc := secretsmanager.NewFromConfig(p.config)
+	svc := p.client
 
 	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
 
@@ -29,36 +32,123 @@ func (p *Serializer) Delete(
 
 	for _, path := range paths {
 
-		err := internalDelete(
-			svc,
-			secretsmanager.DeleteSecretInput{SecretId: aws.String(path),
-				ForceDeleteWithoutRecovery: aws.Bool(true)},
-		)
+		val := m[path]
+
+		input, err := p.deleteInput(path, val)
+		if err == nil {
+			err = internalDelete(svc, input)
+		}
 
 		if err != nil {
 
-			if val, ok := m[path]; ok {
-				im[val.FqName] = support.FullNameField{
-					RemoteName: path,
-					LocalName:  val.FqName,
-					Field:      val.Field,
-					Value:      val.Value,
-					Error:      err,
-				}
+			im[val.FqName] = support.FullNameField{
+				RemoteName: path,
+				LocalName:  val.FqName,
+				Field:      val.Field,
+				Value:      val.Value,
+				Error:      err,
+			}
+
+		}
+	}
+
+	return im, nil
+}
+
+// Restore cancels the scheduled deletion of the secrets found in nodes. It is
+// only possible to restore secrets that was deleted with a recovery window and
+// that still is within that window. Secrets that is not scheduled for deletion
+// are left untouched.
+func (p *Serializer) Restore(
+	node *parser.StructNode,
+	filter *support.FieldFilters) (map[string]support.FullNameField, error) {
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
+
+	im := map[string]support.FullNameField{}
+
+	for _, path := range parser.ExtractPaths(m) {
+
+		_, err := p.client.RestoreSecret(context.Background(),
+			&secretsmanager.RestoreSecretInput{SecretId: aws.String(path)})
+
+		if err != nil {
+
+			val := m[path]
+			im[val.FqName] = support.FullNameField{
+				RemoteName: path,
+				LocalName:  val.FqName,
+				Field:      val.Field,
+				Value:      val.Value,
+				Error:      err,
 			}
 
+			log.Debug().Str("svc", p.service).Str("method", "Restore").
+				Msgf("failed to restore %s error: %v", path, err)
+
+		} else {
+
+			log.Debug().Str("svc", p.service).Str("method", "Restore").Msgf("restored %s", path)
+
 		}
 	}
 
 	return im, nil
 }
 
+// deleteInput creates the delete input for the secret. The tag recovery window
+// takes precedence over the serializer recovery window. A window of zero days
+// deletes the secret without any recovery.
+func (p *Serializer) deleteInput(path string,
+	node *parser.StructNode) (secretsmanager.DeleteSecretInput, error) {
+
+	days := p.recoveryWindow
+	if tag, ok := ToAsmTag(node); ok {
+
+		if window, ok, err := tag.RecoveryWindow(); err != nil {
+			return secretsmanager.DeleteSecretInput{}, err
+		} else if ok {
+			days = window
+		}
+
+	}
+
+	return deleteSecretInput(path, days)
+}
+
+func deleteSecretInput(path string, days int64) (secretsmanager.DeleteSecretInput, error) {
+
+	if days == 0 {
+		return secretsmanager.DeleteSecretInput{SecretId: aws.String(path),
+			ForceDeleteWithoutRecovery: aws.Bool(true)}, nil
+	}
+
+	if err := ValidateRecoveryWindow(days); err != nil {
+		return secretsmanager.DeleteSecretInput{}, err
+	}
+
+	return secretsmanager.DeleteSecretInput{SecretId: aws.String(path),
+		RecoveryWindowInDays: aws.Int64(days)}, nil
+}
+
+// ValidateRecoveryWindow checks that days is either zero (no recovery) or
+// within MinRecoveryWindow and MaxRecoveryWindow.
+func ValidateRecoveryWindow(days int64) error {
+	if days != 0 && (days < MinRecoveryWindow || days > MaxRecoveryWindow) {
+		return errors.Errorf("Recovery window must be between %d and %d days, got %d",
+			MinRecoveryWindow, MaxRecoveryWindow, days)
+	}
+
+	return nil
+}
+
 // DeleteTree will delete all secrets that have a certain prefix.
 // Since it is possible to specify many _prefixes_ this is able
 // to delete several trees.
 func (p *Serializer) DeleteTree(prefixes ...string) error {
 
-	svc := secretsmanager.NewFromConfig(p.config)
+	svc := p.client
 	input := secretsmanager.ListSecretsInput{}
 
 	for {
@@ -80,11 +170,12 @@ func (p *Serializer) DeleteTree(prefixes ...string) error {
 
 			if findPrefix(prefixes, *s.Name) {
 
-				internalDelete(
-					svc,
-					secretsmanager.DeleteSecretInput{SecretId: aws.String(*s.Name),
-						ForceDeleteWithoutRecovery: aws.Bool(true)},
-				)
+				input, err := deleteSecretInput(*s.Name, p.recoveryWindow)
+				if err != nil {
+					return err
+				}
+
+				internalDelete(svc, input)
 
 			}
 
@@ -116,7 +207,7 @@ func findPrefix(array []string, val string) bool {
 
 func internalDelete(svc *secretsmanager.Client, prms secretsmanager.DeleteSecretInput) error {
 
-	fmt.Printf("deleting-asm %v", prms)
+	log.Debug().Msgf("deleting asm-secret %s", aws.ToString(prms.SecretId))
 
 	if _, err := svc.DeleteSecret(context.Background(), &prms); err != nil {
 
@@ -126,7 +217,7 @@ func internalDelete(svc *secretsmanager.Client, prms secretsmanager.DeleteSecret
 			return nil
 		}
 
-		log.Warn().Msgf("Error when deleting %v", prms)
+		log.Warn().Msgf("Error when deleting asm-secret %s", aws.ToString(prms.SecretId))
 		return err
 	}
 
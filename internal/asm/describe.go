@@ -0,0 +1,73 @@
+package asm
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+// Describe fetches the live state, including the current secret string, of the
+// secrets by their remote names. Secrets that do not exist, or are scheduled for
+// deletion, are not part of the result.
+func (p *Serializer) Describe(names []string) (map[string]support.RemoteState, error) {
+
+	result := map[string]support.RemoteState{}
+
+	for _, name := range names {
+		desc, err := p.client.DescribeSecret(context.Background(),
+			&secretsmanager.DescribeSecretInput{SecretId: aws.String(name)})
+
+		if err != nil {
+			var nf *types.ResourceNotFoundException
+			if errors.As(err, &nf) {
+				continue
+			}
+
+			return nil, errors.Wrapf(err, "Failed to describe secret %s", name)
+		}
+
+		if desc.DeletedDate != nil {
+			continue
+		}
+
+		value, err := p.client.GetSecretValue(context.Background(),
+			&secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to get secret %s", name)
+		}
+
+		state := support.RemoteState{
+			Name:         name,
+			Value:        aws.ToString(value.SecretString),
+			Version:      aws.ToString(value.VersionId),
+			LastModified: aws.ToTime(desc.LastChangedDate),
+			KeyID:        aws.ToString(desc.KmsKeyId),
+			Description:  aws.ToString(desc.Description),
+			Tags:         map[string]string{},
+		}
+
+		for _, tag := range desc.Tags {
+			state.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+
+		policy, err := p.client.GetResourcePolicy(context.Background(),
+			&secretsmanager.GetResourcePolicyInput{SecretId: aws.String(name)})
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to get resource policy of secret %s", name)
+		}
+
+		if policy.ResourcePolicy != nil {
+			state.Policies = []string{aws.ToString(policy.ResourcePolicy)}
+		}
+
+		result[name] = state
+	}
+
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package asm
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/pkg/errors"
+)
+
+// generatedSecrets returns the secret to create, where the strkey is generated
+// according to the tag generate policy, and a function that returns the secret to
+// use when updated, where the existing strkey value is retained. When a generate
+// policy is set the existing secret is read first and, if it exists, create is nil
+// and nothing is generated. If no generate policy is set on the tag, prm is used as is.
+func (p *Serializer) generatedSecrets(node *parser.StructNode,
+	prm secretsmanager.CreateSecretInput) (*secretsmanager.CreateSecretInput,
+	func() (secretsmanager.CreateSecretInput, error), error) {
+
+	update := func() (secretsmanager.CreateSecretInput, error) { return prm, nil }
+
+	nasm, ok := ToAsmTag(node)
+	if !ok {
+		return &prm, update, nil
+	}
+
+	policy, err := nasm.GeneratePolicy()
+	if err != nil || policy == nil {
+		return &prm, update, err
+	}
+
+	existing, found, err := p.existingSecret(*prm.Name)
+	if err != nil {
+		return nil, update, err
+	}
+
+	update = func() (secretsmanager.CreateSecretInput, error) {
+		if !found {
+			// Created after it was read, e.g. by someone else
+			if existing, found, err = p.existingSecret(*prm.Name); err != nil {
+				return prm, err
+			}
+		}
+
+		updated := prm
+		document, err := preserveGenerated(policy, existing, aws.ToString(prm.SecretString))
+		if err != nil {
+			return prm, err
+		}
+
+		updated.SecretString = aws.String(document)
+		return updated, nil
+	}
+
+	if found {
+		return nil, update, nil
+	}
+
+	create := prm
+	document, err := p.generate(policy, aws.ToString(prm.SecretString))
+	if err != nil {
+		return nil, update, err
+	}
+
+	create.SecretString = aws.String(document)
+	return &create, update, nil
+}
+
+// generate fills the strkey JSON key in the document with a generated value
+// unless the key already has a value.
+func (p *Serializer) generate(policy *GeneratePolicy, document string) (string, error) {
+	value, ok, err := common.ExtractJSONKey(document, policy.Key)
+	if err != nil {
+		return "", err
+	}
+
+	if ok && value != "" {
+		return document, nil
+	}
+
+	generated, err := p.randomValue(policy)
+	if err != nil {
+		return "", err
+	}
+
+	return common.SetJSONStringKey(document, policy.Key, generated)
+}
+
+// randomValue generates a value locally when the policy specifies the characters to
+// use, otherwise the secrets manager GetRandomPassword is used.
+func (p *Serializer) randomValue(policy *GeneratePolicy) (string, error) {
+	if policy.Chars != "" {
+		return common.RandomString(policy.Length, policy.Chars, policy.Exclude)
+	}
+
+	input := &secretsmanager.GetRandomPasswordInput{PasswordLength: aws.Int64(int64(policy.Length))}
+	if policy.Exclude != "" {
+		input.ExcludeCharacters = aws.String(policy.Exclude)
+	}
+
+	resp, err := p.client.GetRandomPassword(context.Background(), input)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to generate random value for %s", policy.Key)
+	}
+
+	return aws.ToString(resp.RandomPassword), nil
+}
+
+// existingSecret reads the current secret string, it returns false if the secret
+// do not exist.
+func (p *Serializer) existingSecret(name string) (string, bool, error) {
+	resp, err := p.client.GetSecretValue(context.Background(),
+		&secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+
+	if err != nil {
+		var resourceNotFound *types.ResourceNotFoundException
+		if errors.As(err, &resourceNotFound) {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return aws.ToString(resp.SecretString), true, nil
+}
+
+// preserveGenerated copies the strkey value from the existing secret into the document
+// so a generated value is never overwritten when the secret is updated.
+func preserveGenerated(policy *GeneratePolicy, existing string, document string) (string, error) {
+	value, ok, err := common.ExtractJSONKey(existing, policy.Key)
+	if err != nil || !ok || value == "" {
+		return document, err
+	}
+
+	return common.SetJSONStringKey(document, policy.Key, value)
+}
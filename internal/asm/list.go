@@ -0,0 +1,68 @@
+package asm
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/pkg/errors"
+)
+
+// List lists the names of all secrets that begins with any of the prefixes. Secrets
+// that are scheduled for deletion are not included.
+func (p *Serializer) List(prefixes ...string) ([]string, error) {
+
+	names := []string{}
+
+	for _, prefix := range prefixes {
+
+		input := &secretsmanager.ListSecretsInput{
+			Filters: []types.Filter{{Key: types.FilterNameStringTypeName, Values: []string{prefix}}},
+		}
+
+		for {
+			resp, err := p.client.ListSecrets(context.Background(), input)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to list asm-secrets with prefix %s", prefix)
+			}
+
+			for _, s := range resp.SecretList {
+				// The name filter is a prefix match on words - verify exact prefix
+				if name := aws.ToString(s.Name); findPrefix([]string{prefix}, name) {
+					names = append(names, name)
+				}
+			}
+
+			if resp.NextToken == nil {
+				break
+			}
+
+			input.NextToken = resp.NextToken
+		}
+	}
+
+	return names, nil
+}
+
+// DeleteNames deletes the secrets by their remote names using the serializer recovery
+// window. Secrets that do not exist are ignored. Any failed deletion is returned keyed
+// by name.
+func (p *Serializer) DeleteNames(names []string) map[string]error {
+
+	failed := map[string]error{}
+
+	for _, name := range names {
+
+		input, err := deleteSecretInput(name, p.recoveryWindow)
+		if err == nil {
+			err = internalDelete(p.client, input)
+		}
+
+		if err != nil {
+			failed[name] = err
+		}
+	}
+
+	return failed
+}
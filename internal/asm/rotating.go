@@ -0,0 +1,80 @@
+package asm
+
+import (
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/rs/zerolog/log"
+)
+
+// isRotatingSecret returns true if the node is a support.RotatingSecret field
+func isRotatingSecret(node *parser.StructNode) bool {
+	return node.Value.IsValid() && node.Value.Type() == support.RotatingSecretType
+}
+
+// fetchAlternates fetches the alternate stage of all found secrets that are read into
+// a support.RotatingSecret field. The alternate version is looked up using the vs
+// handling in getFromAws. A missing alternate version (e.g. no rotation is ongoing)
+// is not an error.
+func (p *Serializer) fetchAlternates(found *fetched,
+	m map[string]*parser.StructNode) (*fetched, error) {
+
+	names := []string{}
+	alt := map[string]*parser.StructNode{}
+
+	for prm := range found.found {
+		node, ok := m[prm]
+		if !ok || !isRotatingSecret(node) {
+			continue
+		}
+
+		nasm, ok := ToAsmTag(node)
+		if !ok {
+			continue
+		}
+
+		// Same node but with the alternate stage as version stage
+		tag := &AsmTagStruct{StructTagImpl: nasm.StructTagImpl}
+		tag.Named = map[string]string{"vs": nasm.AlternateStage()}
+
+		alt[prm] = &parser.StructNode{Tag: map[string]parser.StructTag{"asm": tag}}
+		names = append(names, prm)
+	}
+
+	if len(names) == 0 {
+		return newFetched(), nil
+	}
+
+	log.Debug().Str("svc", p.service).Str("method", "fetchAlternates").
+		Msgf("Fetching alternates: %v", names)
+
+	return p.parallelGetFromAws(names, alt)
+}
+
+// setAlternates sets the alternate secret string and stage on each found
+// support.RotatingSecret field in groups.
+func setAlternates(found *fetched, alternates *fetched, groups map[string][]*parser.StructNode) {
+	for prm, nodes := range groups {
+		if _, ok := found.found[prm]; !ok {
+			continue
+		}
+
+		for _, node := range nodes {
+			if !isRotatingSecret(node) {
+				continue
+			}
+
+			nasm, ok := ToAsmTag(node)
+			if !ok {
+				continue
+			}
+
+			value := ""
+			if result, ok := alternates.found[prm]; ok && result.SecretString != nil {
+				value = *result.SecretString
+			}
+
+			node.Value.FieldByName("Alternate").SetString(value)
+			node.Value.FieldByName("AlternateStage").SetString(nasm.AlternateStage())
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
-	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/mariotoffia/ssm/internal/common"
 	"github.com/mariotoffia/ssm/parser"
 	"github.com/mariotoffia/ssm/support"
@@ -14,15 +13,48 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// DefaultConcurrency is the default number of parallel GetSecretValue requests
+// used when secrets can not be fetched using BatchGetSecretValue.
+const DefaultConcurrency = 5
+
+const (
+	// MinRecoveryWindow is the minimum number of days a deleted secret may be restored
+	MinRecoveryWindow int64 = 7
+	// MaxRecoveryWindow is the maximum number of days a deleted secret may be restored
+	MaxRecoveryWindow int64 = 30
+)
+
 // Serializer handles the secrets manager communication
 type Serializer struct {
-	config  aws.Config
+	// AWS Config to use when communicating
+	config aws.Config
+	// The name of the service using this library
 	service string
+	// client is created once and reused for all operations
+	client *secretsmanager.Client
+	// concurrency is the max number of parallel requests when not batched
+	concurrency int
+	// recoveryWindow is the default number of days a deleted secret may be
+	// restored. Zero means that it is deleted without any recovery.
+	recoveryWindow int64
 }
 
 // NewFromConfig creates a repository using a existing configuration
 func NewFromConfig(config aws.Config, service string) *Serializer {
-	return &Serializer{config: config, service: service}
+	return &Serializer{config: config, service: service,
+		client: secretsmanager.NewFromConfig(config), concurrency: DefaultConcurrency}
+}
+
+// SetConcurrency sets the max number of parallel GetSecretValue requests that
+// is used for secrets that can not be batch fetched (e.g. when vid or vs is set).
+// If zero or less, DefaultConcurrency is used.
+func (p *Serializer) SetConcurrency(concurrency int) *Serializer {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	p.concurrency = concurrency
+	return p
 }
 
 // New creates a repository using the default configuration.
@@ -33,7 +65,19 @@ func New(service string) (*Serializer, error) {
 		return &Serializer{}, errors.Wrapf(err, "Failed to load AWS config")
 	}
 
-	return &Serializer{config: awscfg, service: service}, nil
+	return NewFromConfig(awscfg, service), nil
+}
+
+// SetRecoveryWindow sets the default number of days (MinRecoveryWindow - MaxRecoveryWindow)
+// that a deleted secret may be restored. Zero deletes the secret without any recovery,
+// which is the default. The tag recovery overrides this value per secret.
+func (p *Serializer) SetRecoveryWindow(days int64) error {
+	if err := ValidateRecoveryWindow(days); err != nil {
+		return err
+	}
+
+	p.recoveryWindow = days
+	return nil
 }
 
 // Get parameters from the secrets manager and populates the node graph with values.
@@ -43,7 +87,9 @@ func (p *Serializer) Get(node *parser.StructNode,
 	filter *support.FieldFilters) (map[string]support.FullNameField, error) {
 
 	m := map[string]*parser.StructNode{}
+	groups := map[string][]*parser.StructNode{}
 	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
+	parser.NodesToParameterGroups(node, groups, filter, []string{"asm"})
 	mprms := map[string]*secretsmanager.GetSecretValueOutput{}
 	im := map[string]support.FullNameField{}
 	extrprms := parser.ExtractPaths(m)
@@ -53,42 +99,42 @@ func (p *Serializer) Get(node *parser.StructNode,
 		Str("method", "Get").
 		Msgf("Fetching: %v", extrprms)
 
-	for _, prm := range extrprms {
-		if n, ok := m[prm]; ok {
-
-			if nasm, ok := ToAsmTag(n); ok {
-				result, err := p.getFromAws(prm, nasm)
-
-				if err != nil {
-
-					var resourceNotFound *types.ResourceNotFoundException
-
-					if errors.As(err, &resourceNotFound) {
-
-						im[n.FqName] = support.FullNameField{LocalName: n.FqName,
-							RemoteName: prm, Field: node.Field, Value: node.Value}
-
-					} else {
-
-						return nil, errors.Wrapf(err, "Failed fetch asm config entry %s", prm)
-
-					}
-
-				} else {
+	result, err := p.fetch(extrprms, m)
+	if err != nil {
+		return nil, err
+	}
 
-					log.Debug().Str("svc", p.service).Str("method", "Get").Msgf("field %s", n.FqName)
-					mprms[n.FqName] = result
+	alternates, err := p.fetchAlternates(result, m)
+	if err != nil {
+		return nil, err
+	}
 
-				}
-			} else {
+	for _, prm := range result.missing {
+		for _, gn := range groups[prm] {
+			im[gn.FqName] = support.FullNameField{LocalName: gn.FqName,
+				RemoteName: prm, Field: gn.Field, Value: gn.Value}
+		}
+	}
 
-				log.Warn().Str("svc", p.service).Msgf("tag is not asm tag! tag: %v", n)
+	// Soft deleted secrets are reported with ErrScheduledForDeletion
+	for _, prm := range result.deleted {
+		for _, gn := range groups[prm] {
+			im[gn.FqName] = support.FullNameField{LocalName: gn.FqName,
+				RemoteName: prm, Field: gn.Field, Value: gn.Value,
+				Error: support.ErrScheduledForDeletion}
+		}
+	}
 
-			}
+	for prm, result := range result.found {
+		// All fields sharing the secret (jsonkey) gets the same result
+		for _, gn := range groups[prm] {
+			log.Debug().Str("svc", p.service).Str("method", "Get").Msgf("field %s", gn.FqName)
+			mprms[gn.FqName] = result
 		}
 	}
 
 	populate(node, mprms)
+	setAlternates(result, alternates, groups)
 
 	return im, nil
 }
@@ -98,19 +144,48 @@ func (p *Serializer) Upsert(node *parser.StructNode,
 	filter *support.FieldFilters) map[string]support.FullNameField {
 
 	m := map[string]*parser.StructNode{}
+	groups := map[string][]*parser.StructNode{}
 	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
+	parser.NodesToParameterGroups(node, groups, filter, []string{"asm"})
 
-	// TODO: Implement me!
 	im := map[string]support.FullNameField{}
 
-	client := secretsmanager.NewFromConfig(p.config)
+	client := p.client
 	params := genCreateSecretParams(m)
 
 	for _, prm := range params {
 		node := m[*prm.Name]
 
-		_, err := p.createAwsSecret(client, prm)
+		if group := groups[*prm.Name]; common.IsJSONKeyGroup(group, "asm") {
+			doc, err := p.mergeJSONKeys(client, *prm.Name, group)
+			if err != nil {
+				im[node.FqName] = support.FullNameField{LocalName: node.FqName,
+					RemoteName: *prm.Name, Error: err, Field: node.Field, Value: node.Value}
+				continue
+			}
+
+			prm.SecretString = aws.String(doc)
+		}
+
+		create, update, err := p.generatedSecrets(node, prm)
 		if err != nil {
+			im[node.FqName] = support.FullNameField{LocalName: node.FqName,
+				RemoteName: *prm.Name, Error: err, Field: node.Field, Value: node.Value}
+			continue
+		}
+
+		if create != nil {
+			_, err = p.createAwsSecret(client, *create)
+		}
+
+		if create == nil || err != nil {
+			prm, err = update()
+			if err != nil {
+				im[node.FqName] = support.FullNameField{LocalName: node.FqName,
+					RemoteName: *prm.Name, Error: err, Field: node.Field, Value: node.Value}
+				continue
+			}
+
 			_, err := p.updateAwsSecret(client, prm)
 			if err != nil {
 				im[node.FqName] = support.FullNameField{LocalName: node.FqName,
@@ -138,7 +213,13 @@ func populate(node *parser.StructNode, params map[string]*secretsmanager.GetSecr
 	if val, ok := params[node.FqName]; ok {
 		if tag, ok := node.Tag["asm"]; ok {
 			if tag.GetFullName() != "" {
-				common.SetStructValueFromString(node, *val.Name, *val.SecretString)
+				if key := common.JSONKey(node, "asm"); key != "" {
+					if _, err := common.SetStructValueFromJSONKey(node, *val.Name, *val.SecretString, key); err != nil {
+						log.Warn().Msgf("failed to read jsonkey %s from %s error: %v", key, *val.Name, err)
+					}
+				} else {
+					common.SetStructValueFromString(node, *val.Name, *val.SecretString)
+				}
 			}
 		}
 	}
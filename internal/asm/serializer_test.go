@@ -253,3 +253,299 @@ func TestMarshalSubStructAsJSON(t *testing.T) {
 	assert.Equal(t, 1088, testr.Connection.Timeout)
 	assert.Equal(t, "åaaäs2##!!äöå!#dfmklvmlkBBCH2¤", testr.Connection.Password)
 }
+
+func TestUnmarshalJSONKeyFieldsFromSingleSecret(t *testing.T) {
+	var test testsupport.JSONKeyAsmStruct
+	tp := reflect.ValueOf(&test)
+
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(tp)
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	asmr, err := New("test-service")
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	_, err = asmr.Get(node, support.NewFilters())
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	assert.Equal(t, "admin", test.User)
+	assert.Equal(t, "s3cr3t", test.Password)
+	assert.Equal(t, "db.local", test.Host)
+	assert.Equal(t, 5432, test.Port)
+}
+
+func TestMarshalJSONKeyFieldsKeepsSiblingKeys(t *testing.T) {
+	if scope != "rw" {
+		return
+	}
+
+	type PasswordOnly struct {
+		Password string `asm:"rds, jsonkey=password"`
+	}
+
+	test := PasswordOnly{Password: "n3w-s3cr3t"}
+	tp := reflect.ValueOf(&test)
+
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(tp)
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	asmr, err := New("test-service")
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	result := asmr.Upsert(node, support.NewFilters())
+	if len(result) > 0 {
+		assert.Equal(t, nil, result)
+	}
+
+	var testr testsupport.JSONKeyAsmStruct
+	tpr := reflect.ValueOf(&testr)
+
+	node, err = parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(tpr)
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	_, err = asmr.Get(node, support.NewFilters())
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	assert.Equal(t, "admin", testr.User)
+	assert.Equal(t, "n3w-s3cr3t", testr.Password)
+	assert.Equal(t, 5432, testr.Port)
+}
+
+func TestRecoveryWindowTagOverridesSerializer(t *testing.T) {
+	type Test struct {
+		Soft  string `asm:"soft, recovery=7"`
+		Hard  string `asm:"hard, recovery=0"`
+		Dflt  string `asm:"dflt"`
+		Wrong string `asm:"wrong, recovery=3"`
+	}
+
+	var test Test
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(reflect.ValueOf(&test))
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	asmr, err := New("test-service")
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	assert.NotEqual(t, nil, asmr.SetRecoveryWindow(31))
+	assert.Equal(t, nil, asmr.SetRecoveryWindow(30))
+
+	input, err := asmr.deleteInput("soft", &node.Childs[0])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(7), *input.RecoveryWindowInDays)
+
+	input, err = asmr.deleteInput("hard", &node.Childs[1])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, *input.ForceDeleteWithoutRecovery)
+
+	input, err = asmr.deleteInput("dflt", &node.Childs[2])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(30), *input.RecoveryWindowInDays)
+
+	_, err = asmr.deleteInput("wrong", &node.Childs[3])
+	assert.NotEqual(t, nil, err)
+}
+
+func TestSoftDeletedSecretIsReportedAsScheduledForDeletion(t *testing.T) {
+	if scope != "rw" {
+		return
+	}
+
+	type Test struct {
+		Name string `asm:"softdelete, recovery=7"`
+	}
+
+	test := Test{Name: "to be deleted"}
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(reflect.ValueOf(&test))
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	asmr, err := New("test-service")
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	result := asmr.Upsert(node, support.NewFilters())
+	assert.Equal(t, 0, len(result))
+
+	result, err = asmr.Delete(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(result))
+
+	result, err = asmr.Get(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, support.ErrScheduledForDeletion, result["Name"].Error)
+
+	result, err = asmr.Restore(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(result))
+
+	test.Name = ""
+	_, err = asmr.Get(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "to be deleted", test.Name)
+}
+
+func TestRotatingSecretGetsCurrentAndPreviousVersion(t *testing.T) {
+	if scope != "rw" {
+		return
+	}
+
+	type Test struct {
+		Db support.RotatingSecret `asm:"rotating"`
+	}
+
+	test := Test{Db: support.RotatingSecret{Current: "first"}}
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(reflect.ValueOf(&test))
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	asmr, err := New("test-service")
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	result := asmr.Upsert(node, support.NewFilters())
+	assert.Equal(t, 0, len(result))
+
+	test.Db.Current = "second"
+	result = asmr.Upsert(node, support.NewFilters())
+	assert.Equal(t, 0, len(result))
+
+	test.Db = support.RotatingSecret{}
+	_, err = asmr.Get(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, "second", test.Db.Current)
+	assert.Equal(t, "first", test.Db.Alternate)
+	assert.Equal(t, support.StagePrevious, test.Db.AlternateStage)
+}
+
+func TestGeneratePolicyFromTag(t *testing.T) {
+	type Test struct {
+		None   string `asm:"none, strkey=password"`
+		Remote string `asm:"remote, strkey=password, genexclude=/@"`
+		Local  string `asm:"local, strkey=password, genlen=8, genchars=abc"`
+		Wrong  string `asm:"wrong, strkey=password, genlen=-1"`
+	}
+
+	var test Test
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(reflect.ValueOf(&test))
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	policy := func(i int) (*GeneratePolicy, error) {
+		tag, _ := ToAsmTag(&node.Childs[i])
+		return tag.GeneratePolicy()
+	}
+
+	p, err := policy(0)
+	assert.Equal(t, nil, err)
+	assert.Nil(t, p)
+
+	p, err = policy(1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &GeneratePolicy{Key: "password", Length: DefaultGenerateLength, Exclude: "/@"}, p)
+
+	p, err = policy(2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &GeneratePolicy{Key: "password", Length: 8, Chars: "abc"}, p)
+
+	_, err = policy(3)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestVersionWithStage(t *testing.T) {
+	versions := []support.SecretVersion{
+		{VersionID: "v1", Stages: []string{"AWSCURRENT"}},
+		{VersionID: "v2", Stages: []string{"AWSPREVIOUS", "CANARY"}},
+	}
+
+	assert.Equal(t, "v1", versionWithStage(versions, "AWSCURRENT"))
+	assert.Equal(t, "v2", versionWithStage(versions, "CANARY"))
+	assert.Equal(t, "", versionWithStage(versions, "AWSPENDING"))
+}
+
+func TestMarshalStagedAndPromote(t *testing.T) {
+	if scope != "rw" {
+		return
+	}
+
+	type Test struct {
+		Name string `asm:"staged"`
+	}
+
+	test := Test{Name: "current"}
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("asm", NewTagParser()).
+		Parse(reflect.ValueOf(&test))
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	asmr, err := New("test-service")
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	assert.Equal(t, 0, len(asmr.Upsert(node, support.NewFilters())))
+
+	test.Name = "canary"
+	assert.Equal(t, 0, len(asmr.UpsertStaged(node, support.NewFilters(), "CANARY")))
+
+	test.Name = ""
+	_, err = asmr.Get(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "current", test.Name)
+
+	assert.Equal(t, 0, len(asmr.MoveStage(node, support.NewFilters(), "AWSCURRENT", "CANARY")))
+
+	_, err = asmr.Get(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "canary", test.Name)
+
+	versions, err := asmr.Versions(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(versions["Name"]))
+}
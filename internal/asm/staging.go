@@ -0,0 +1,210 @@
+package asm
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/google/uuid"
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Versions lists all versions, along with the staging labels, of each secret in
+// the node tree. The result is keyed by the local field name.
+func (p *Serializer) Versions(node *parser.StructNode,
+	filter *support.FieldFilters) (map[string][]support.SecretVersion, error) {
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
+
+	result := map[string][]support.SecretVersion{}
+
+	for name, n := range m {
+		versions, err := p.listVersions(name)
+		if err != nil {
+			return nil, err
+		}
+
+		result[n.FqName] = versions
+	}
+
+	return result, nil
+}
+
+// MoveStage moves the stage onto the version that has the fromStage attached, for
+// each secret in the node tree. For example, moving AWSCURRENT from CANARY promotes
+// the canary version to the current version. Any field that failed is returned.
+func (p *Serializer) MoveStage(node *parser.StructNode,
+	filter *support.FieldFilters, stage string, fromStage string) map[string]support.FullNameField {
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
+
+	im := map[string]support.FullNameField{}
+
+	for name, n := range m {
+		versions, err := p.listVersions(name)
+		if err == nil {
+			target, current := versionWithStage(versions, fromStage), versionWithStage(versions, stage)
+
+			if target == "" {
+				err = errors.Errorf("No version of %s has stage %s", name, fromStage)
+			} else if target != current {
+				err = p.updateStage(name, stage, target, current)
+			}
+		}
+
+		if err != nil {
+			im[n.FqName] = support.FullNameField{LocalName: n.FqName,
+				RemoteName: name, Error: err, Field: n.Field, Value: n.Value}
+		}
+	}
+
+	return im
+}
+
+// RemoveStage removes the stage from the version it is attached to, for each secret
+// in the node tree. Secrets where no version has the stage are left untouched. Any
+// field that failed is returned.
+func (p *Serializer) RemoveStage(node *parser.StructNode,
+	filter *support.FieldFilters, stage string) map[string]support.FullNameField {
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
+
+	im := map[string]support.FullNameField{}
+
+	for name, n := range m {
+		versions, err := p.listVersions(name)
+		if err == nil {
+			if current := versionWithStage(versions, stage); current != "" {
+				err = p.updateStage(name, stage, "", current)
+			}
+		}
+
+		if err != nil {
+			im[n.FqName] = support.FullNameField{LocalName: n.FqName,
+				RemoteName: name, Error: err, Field: n.Field, Value: n.Value}
+		}
+	}
+
+	return im
+}
+
+// UpsertStaged writes the node values as a new version of each existing secret with
+// only the stage attached, hence AWSCURRENT is left as is. Use MoveStage to promote
+// the staged version. Any field that failed is returned.
+func (p *Serializer) UpsertStaged(node *parser.StructNode,
+	filter *support.FieldFilters, stage string) map[string]support.FullNameField {
+
+	m := map[string]*parser.StructNode{}
+	groups := map[string][]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"asm"})
+	parser.NodesToParameterGroups(node, groups, filter, []string{"asm"})
+
+	im := map[string]support.FullNameField{}
+
+	for name, n := range m {
+		value := common.GetStringValueFromField(n)
+
+		var err error
+		if group := groups[name]; common.IsJSONKeyGroup(group, "asm") {
+			value, err = p.mergeJSONKeys(p.client, name, group)
+		}
+
+		if err == nil {
+			_, err = p.client.PutSecretValue(context.Background(), &secretsmanager.PutSecretValueInput{
+				SecretId:           aws.String(name),
+				ClientRequestToken: aws.String(uuid.New().String()),
+				SecretString:       aws.String(value),
+				VersionStages:      []string{stage},
+			})
+		}
+
+		if err != nil {
+			im[n.FqName] = support.FullNameField{LocalName: n.FqName,
+				RemoteName: name, Error: err, Field: n.Field, Value: n.Value}
+			continue
+		}
+
+		log.Debug().Str("svc", p.service).Str("method", "UpsertStaged").
+			Msgf("put secret %s value *** with stage %s", name, stage)
+	}
+
+	return im
+}
+
+// listVersions lists all versions of the secret using ListSecretVersionIds
+func (p *Serializer) listVersions(name string) ([]support.SecretVersion, error) {
+	input := &secretsmanager.ListSecretVersionIdsInput{SecretId: aws.String(name)}
+	versions := []support.SecretVersion{}
+
+	for {
+		resp, err := p.client.ListSecretVersionIds(context.Background(), input)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to list versions of %s", name)
+		}
+
+		for _, v := range resp.Versions {
+			versions = append(versions, support.SecretVersion{
+				Name:             name,
+				VersionID:        aws.ToString(v.VersionId),
+				Stages:           v.VersionStages,
+				CreatedDate:      aws.ToTime(v.CreatedDate),
+				LastAccessedDate: aws.ToTime(v.LastAccessedDate),
+			})
+		}
+
+		if resp.NextToken == nil {
+			break
+		}
+
+		input.NextToken = resp.NextToken
+	}
+
+	return versions, nil
+}
+
+// updateStage moves the stage to the moveTo version and removes it from the
+// removeFrom version. Either may be empty.
+func (p *Serializer) updateStage(name string, stage string, moveTo string, removeFrom string) error {
+	input := &secretsmanager.UpdateSecretVersionStageInput{
+		SecretId:     aws.String(name),
+		VersionStage: aws.String(stage),
+	}
+
+	if moveTo != "" {
+		input.MoveToVersionId = aws.String(moveTo)
+	}
+
+	if removeFrom != "" {
+		input.RemoveFromVersionId = aws.String(removeFrom)
+	}
+
+	if _, err := p.client.UpdateSecretVersionStage(context.Background(), input); err != nil {
+		return errors.Wrapf(err, "Failed to move stage %s on %s", stage, name)
+	}
+
+	log.Debug().Str("svc", p.service).Str("method", "updateStage").
+		Msgf("moved stage %s on %s to '%s' from '%s'", stage, name, moveTo, removeFrom)
+
+	return nil
+}
+
+// versionWithStage returns the version id of the version having the stage attached
+// or an empty string if none.
+func versionWithStage(versions []support.SecretVersion, stage string) string {
+	for _, v := range versions {
+		for _, s := range v.Stages {
+			if s == stage {
+				return v.VersionID
+			}
+		}
+	}
+
+	return ""
+}
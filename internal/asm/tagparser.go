@@ -20,6 +20,12 @@ func NewTagParser() parser.TagParser {
 			"vid",
 			"vs",
 			"strkey",
+			"jsonkey",
+			"recovery",
+			"alt",
+			"genlen",
+			"genchars",
+			"genexclude",
 		}),
 	}
 }
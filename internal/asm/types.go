@@ -1,9 +1,12 @@
 package asm
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
 )
 
 // ParamTier specifies the parameter tier such as std, adv, or intelligent.
@@ -18,6 +21,22 @@ func ToAsmTag(generictag *parser.StructNode) (*AsmTagStruct, bool) {
 	return nil, false
 }
 
+// DefaultGenerateLength is the length of generated strkey values when genlen is not set
+const DefaultGenerateLength = 32
+
+// GeneratePolicy specifies how the strkey value is generated when a secret is created.
+type GeneratePolicy struct {
+	// Key is the strkey i.e. the JSON key to generate into
+	Key string
+	// Length is the number of characters to generate
+	Length int
+	// Chars is the characters to pick from. When set, the value is generated locally
+	// otherwise the secrets manager GetRandomPassword is used.
+	Chars string
+	// Exclude is characters that must not be part of the generated value
+	Exclude string
+}
+
 // AsmTag is a generic interface
 type AsmTag interface {
 	parser.StructTag
@@ -33,6 +52,10 @@ type AsmTag interface {
 	StringKey() string
 	VersionStage() string
 	VersionID() string
+	JSONKey() string
+	RecoveryWindow() (int64, bool, error)
+	AlternateStage() string
+	GeneratePolicy() (*GeneratePolicy, error)
 }
 
 // AsmTagStruct is for AWS secets manager
@@ -50,12 +73,70 @@ func (t *AsmTagStruct) VersionID() string { return t.StructTagImpl.Named["vid"]
 func (t *AsmTagStruct) VersionStage() string { return t.StructTagImpl.Named["vs"] }
 
 // StringKey is the name of the element in the JSON payload in value where secrets
-// manager shall generate it's password into. This is done in creation time. The
-// complete secret string is then encrypted. If this is nil, no generation is wanted.
-// This is not used by the ssm go implementation. Instead this is for cloud formation
-// that have the ability to generate a password upon deployment.
+// manager shall generate it's password into. If this is empty, no generation is wanted.
+// When any of genlen, genchars or genexclude is set, see GeneratePolicy, the value is
+// generated when the secret is created and the existing value is kept when the secret
+// is updated. The cloud formation and cdk renderers use it to generate the value upon
+// deployment.
 func (t *AsmTagStruct) StringKey() string { return t.StructTagImpl.Named["strkey"] }
 
+// GeneratePolicy returns the policy of how to generate the strkey value when the secret
+// is created. If none of genlen, genchars or genexclude is set on the tag, or strkey is
+// not set, nil is returned and no value is generated.
+func (t *AsmTagStruct) GeneratePolicy() (*GeneratePolicy, error) {
+	named := t.StructTagImpl.Named
+	if t.StringKey() == "" || (named["genlen"] == "" && named["genchars"] == "" && named["genexclude"] == "") {
+		return nil, nil
+	}
+
+	policy := &GeneratePolicy{Key: t.StringKey(), Length: DefaultGenerateLength,
+		Chars: named["genchars"], Exclude: named["genexclude"]}
+
+	if str := named["genlen"]; str != "" {
+		length, err := strconv.Atoi(str)
+		if err != nil || length <= 0 {
+			return nil, errors.Errorf("Invalid genlen %s", str)
+		}
+
+		policy.Length = length
+	}
+
+	return policy, nil
+}
+
+// JSONKey is the top level key or JSON pointer (when starting with a slash) that
+// this field reads out of the secret JSON document. Several fields may share the
+// same secret and read different keys. The secret is fetched only once.
+func (t *AsmTagStruct) JSONKey() string { return t.StructTagImpl.Named["jsonkey"] }
+
+// RecoveryWindow is the number of days a deleted secret may be restored before it is
+// permanently deleted. Zero deletes without any recovery. If not set on the tag, false
+// is returned and the serializer default is used.
+func (t *AsmTagStruct) RecoveryWindow() (int64, bool, error) {
+	str, ok := t.StructTagImpl.Named["recovery"]
+	if !ok || str == "" {
+		return 0, false, nil
+	}
+
+	days, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "Invalid recovery window %s", str)
+	}
+
+	return days, true, nil
+}
+
+// AlternateStage is the staging label of the alternate version that is read into a
+// support.RotatingSecret field along with the current version. If not set on the tag,
+// AWSPREVIOUS is used.
+func (t *AsmTagStruct) AlternateStage() string {
+	if stage := t.StructTagImpl.Named["alt"]; stage != "" {
+		return stage
+	}
+
+	return support.StagePrevious
+}
+
 // Description returns a  description describing the parameter (if any).
 func (t *AsmTagStruct) Description() string { return t.StructTagImpl.Named["description"] }
 
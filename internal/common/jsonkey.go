@@ -0,0 +1,207 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/pkg/errors"
+)
+
+// JSONKey returns the jsonkey named tag parameter on the node for the
+// specified tag name (e.g. pms, asm). If not set an empty string is returned.
+func JSONKey(node *parser.StructNode, tagname string) string {
+	if tag, ok := node.Tag[tagname]; ok {
+		return tag.GetNamed()["jsonkey"]
+	}
+
+	return ""
+}
+
+// IsJSONKeyGroup returns true if any of the nodes do extract a jsonkey
+// out of the remote parameter document.
+func IsJSONKeyGroup(nodes []*parser.StructNode, tagname string) bool {
+	for _, node := range nodes {
+		if JSONKey(node, tagname) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetStructValueFromJSONKey extracts the jsonkey from the document and sets
+// the value onto the node. If the key is not present in the document, the
+// field is left untouched and false is returned.
+func SetStructValueFromJSONKey(node *parser.StructNode, name string,
+	document string, key string) (bool, error) {
+
+	value, ok, err := ExtractJSONKey(document, key)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return true, SetStructValueFromString(node, name, value)
+}
+
+// ExtractJSONKey extracts the value denoted by key from the JSON document. The
+// key is either a plain top level key (e.g. password) or a JSON pointer (RFC 6901)
+// when starting with a slash (e.g. /db/host). String values are returned without
+// quotes, all other values are returned as their JSON representation.
+func ExtractJSONKey(document string, key string) (string, bool, error) {
+	doc, err := decodeJSONDocument(document)
+	if err != nil {
+		return "", false, err
+	}
+
+	var current interface{} = doc
+	for _, token := range JSONKeyTokens(key) {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[token]
+			if !ok {
+				return "", false, nil
+			}
+			current = next
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return "", false, nil
+			}
+			current = v[idx]
+		default:
+			return "", false, nil
+		}
+	}
+
+	if s, ok := current.(string); ok {
+		return s, true, nil
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(data), true, nil
+}
+
+// MergeJSONKeys merges the field values of all nodes that have a jsonkey into
+// the document. All keys in the document that are not referenced by any node
+// are kept as is. An empty document is treated as an empty JSON object.
+func MergeJSONKeys(document string, nodes []*parser.StructNode, tagname string) (string, error) {
+	doc, err := decodeJSONDocument(document)
+	if err != nil {
+		return "", err
+	}
+
+	for _, node := range nodes {
+		key := JSONKey(node, tagname)
+		if key == "" {
+			continue
+		}
+
+		if err := setJSONKey(doc, key, node); err != nil {
+			return "", errors.Wrapf(err, "Failed to set jsonkey %s for field %s", key, node.FqName)
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// SetJSONStringKey sets the top level key in the JSON document to the string value.
+// All other keys are kept as is. An empty document is treated as an empty JSON object.
+func SetJSONStringKey(document string, key string, value string) (string, error) {
+	doc, err := decodeJSONDocument(document)
+	if err != nil {
+		return "", err
+	}
+
+	doc[key] = value
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func setJSONKey(doc map[string]interface{}, key string, node *parser.StructNode) error {
+	value, err := jsonValueFromField(node)
+	if err != nil {
+		return err
+	}
+
+	tokens := JSONKeyTokens(key)
+	current := doc
+
+	for i, token := range tokens {
+		if i == len(tokens)-1 {
+			current[token] = value
+			break
+		}
+
+		next, ok := current[token].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[token] = next
+		}
+
+		current = next
+	}
+
+	return nil
+}
+
+func jsonValueFromField(node *parser.StructNode) (interface{}, error) {
+	if node.Value.Kind() == reflect.String {
+		return node.Value.String(), nil
+	}
+
+	data, err := json.Marshal(node.Value.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(data), nil
+}
+
+func decodeJSONDocument(document string) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	if strings.TrimSpace(document) == "" {
+		return doc, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewBufferString(document))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.Wrapf(err, "Value is not a JSON object")
+	}
+
+	return doc, nil
+}
+
+// JSONKeyTokens splits the key into reference tokens. A plain key is a single
+// token while a JSON pointer is split on slash and unescaped (~1 -> /, ~0 -> ~).
+func JSONKeyTokens(key string) []string {
+	if !strings.HasPrefix(key, "/") {
+		return []string{key}
+	}
+
+	tokens := strings.Split(key[1:], "/")
+	for i := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tokens[i], "~1", "/"), "~0", "~")
+	}
+
+	return tokens
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractJSONKeyTopLevelKey(t *testing.T) {
+	value, ok, err := ExtractJSONKey(`{"username":"admin","port":5432}`, "username")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "admin", value)
+}
+
+func TestExtractJSONKeyNumberIsReturnedAsJSON(t *testing.T) {
+	value, ok, err := ExtractJSONKey(`{"username":"admin","port":5432}`, "port")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "5432", value)
+}
+
+func TestExtractJSONKeyPointer(t *testing.T) {
+	doc := `{"db":{"hosts":["a.local","b.local"],"a/b":{"c~d":true}}}`
+
+	value, ok, err := ExtractJSONKey(doc, "/db/hosts/1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "b.local", value)
+
+	value, ok, err = ExtractJSONKey(doc, "/db/a~1b/c~0d")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "true", value)
+}
+
+func TestExtractJSONKeyMissingKey(t *testing.T) {
+	_, ok, err := ExtractJSONKey(`{"username":"admin"}`, "/db/host")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+}
+
+func TestExtractJSONKeyInvalidDocument(t *testing.T) {
+	_, _, err := ExtractJSONKey(`not json`, "username")
+
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMergeJSONKeysKeepsSiblingKeys(t *testing.T) {
+	type Test struct {
+		Password string `pms:"rds, jsonkey=password"`
+		Port     int    `pms:"rds, jsonkey=/conn/port"`
+	}
+
+	test := Test{Password: "new", Port: 1433}
+	node, err := parser.New("test-service", "dev", "").
+		RegisterTagParser("pms", parser.NewTagParser([]string{"jsonkey"})).
+		Parse(reflect.ValueOf(&test))
+
+	assert.Equal(t, nil, err)
+
+	nodes := []*parser.StructNode{&node.Childs[0], &node.Childs[1]}
+	doc, err := MergeJSONKeys(`{"username":"admin","password":"old","big":12345678901234567890}`, nodes, "pms")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"big":12345678901234567890,"conn":{"port":1433},"password":"new","username":"admin"}`, doc)
+}
+
+func TestSetJSONStringKeyKeepsOtherKeys(t *testing.T) {
+	document, err := SetJSONStringKey(`{"username":"admin","port":5432}`, "password", "s3cr3t")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"password":"s3cr3t","port":5432,"username":"admin"}`, document)
+
+	document, err = SetJSONStringKey("", "password", "s3cr3t")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"password":"s3cr3t"}`, document)
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRandomCharacters is the characters used by RandomString when no
+// characters are specified.
+const DefaultRandomCharacters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!#$%&()*+,-.:;<=>?[]^_{|}~"
+
+// RandomString generates a cryptographically random string of length characters
+// picked from chars (DefaultRandomCharacters if empty) where none of the characters
+// in exclude is used.
+func RandomString(length int, chars string, exclude string) (string, error) {
+	if chars == "" {
+		chars = DefaultRandomCharacters
+	}
+
+	alphabet := []rune{}
+	for _, c := range chars {
+		if !strings.ContainsRune(exclude, c) {
+			alphabet = append(alphabet, c)
+		}
+	}
+
+	if len(alphabet) == 0 || length <= 0 {
+		return "", errors.Errorf("Can not generate random string of length %d", length)
+	}
+
+	result := make([]rune, length)
+	max := big.NewInt(int64(len(alphabet)))
+
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		result[i] = alphabet[n.Int64()]
+	}
+
+	return string(result), nil
+}
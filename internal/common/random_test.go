@@ -0,0 +1,32 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomStringUsesCharsAndExclude(t *testing.T) {
+	value, err := RandomString(100, "abc", "b")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 100, len(value))
+	assert.Equal(t, "", strings.Trim(value, "ac"))
+}
+
+func TestRandomStringDefaultCharacters(t *testing.T) {
+	value, err := RandomString(64, "", "")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 64, len(value))
+	assert.Equal(t, "", strings.Trim(value, DefaultRandomCharacters))
+}
+
+func TestRandomStringAllExcludedFails(t *testing.T) {
+	_, err := RandomString(10, "ab", "ab")
+	assert.NotEqual(t, nil, err)
+
+	_, err = RandomString(0, "", "")
+	assert.NotEqual(t, nil, err)
+}
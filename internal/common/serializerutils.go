@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -19,19 +21,73 @@ func setStructIntValue(rv reflect.Value, name string, value string) error {
 	return nil
 }
 
-// SetStructValueFromString sets a field in a struct to the specified value.
+func setStructUintValue(rv reflect.Value, name string, value string) error {
+	uval, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "Config value %s = %s is not a valid unsigned integer", name, value)
+	}
+	rv.SetUint(uval)
+	return nil
+}
+
+func setStructBoolValue(rv reflect.Value, name string, value string) error {
+	bval, err := strconv.ParseBool(value)
+	if err != nil {
+		return errors.Wrapf(err, "Config value %s = %s is not a valid boolean", name, value)
+	}
+	rv.SetBool(bval)
+	return nil
+}
+
+func setStructFloatValue(rv reflect.Value, name string, value string) error {
+	fval, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errors.Wrapf(err, "Config value %s = %s is not a valid float", name, value)
+	}
+	rv.SetFloat(fval)
+	return nil
+}
+
+// SetStructValueFromString sets a field in a struct to the specified value. Fields
+// with generated code are set without reflection.
 func SetStructValueFromString(node *parser.StructNode, name string, value string) error {
 
 	log.Debug().Msgf("setting: %s (%s)", node.FqName, name)
 
+	if node.Generated != nil {
+		if ok, err := node.Generated.SsmPopulate(node.FqName, value); ok {
+			if err != nil {
+				return errors.Wrapf(err, "Config value %s = %s is not valid for field %s", name, value, node.FqName)
+			}
+
+			return nil
+		}
+	}
+
+	if node.Value.Type() == support.RotatingSecretType {
+		node.Value.FieldByName("Current").SetString(value)
+		return nil
+	}
+
 	switch node.Value.Kind() {
 	case reflect.Struct:
-		setSubStructViaJSONString(node, value)
+		return setSubStructViaJSONString(node, value)
 	case reflect.String:
 		node.Value.SetString(value)
 
 	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Int8:
-		setStructIntValue(node.Value, name, value)
+		return setStructIntValue(node.Value, name, value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setStructUintValue(node.Value, name, value)
+	case reflect.Bool:
+		return setStructBoolValue(node.Value, name, value)
+	case reflect.Float32, reflect.Float64:
+		return setStructFloatValue(node.Value, name, value)
+	case reflect.Slice:
+		// StringList is comma separated
+		if node.Value.Type().Elem() == reflect.TypeOf("") {
+			node.Value.Set(reflect.ValueOf(strings.Split(value, ",")).Convert(node.Value.Type()))
+		}
 	}
 
 	return nil
@@ -58,6 +114,17 @@ func getJSONViaSubStruct(node *parser.StructNode) (string, error) {
 // converts it to a string
 func GetStringValueFromField(node *parser.StructNode) string {
 
+	if node.Generated != nil {
+		if value, ok := node.Generated.SsmMarshal(node.FqName); ok {
+			return value
+		}
+	}
+
+	// Only the current version is written
+	if node.Value.Type() == support.RotatingSecretType {
+		return node.Value.FieldByName("Current").String()
+	}
+
 	switch node.Value.Kind() {
 	case reflect.Struct:
 		data, _ := getJSONViaSubStruct(node)
@@ -74,6 +141,11 @@ func GetStringValueFromField(node *parser.StructNode) string {
 		return strconv.FormatFloat(node.Value.Float(), 'f', -1, 32)
 	case reflect.Float64:
 		return strconv.FormatFloat(node.Value.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		// StringList is comma separated
+		if list, ok := node.Value.Interface().([]string); ok {
+			return strings.Join(list, ",")
+		}
 	}
 
 	return ""
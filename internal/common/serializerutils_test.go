@@ -0,0 +1,40 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+type scalars struct {
+	Flag  bool
+	Count uint16
+	Ratio float64
+	Hosts []string
+}
+
+func scalarNode(v reflect.Value, field string) *parser.StructNode {
+	return &parser.StructNode{FqName: field, Value: v.FieldByName(field)}
+}
+
+func TestSetStructValueFromStringScalars(t *testing.T) {
+	var s scalars
+	v := reflect.ValueOf(&s).Elem()
+
+	assert.NoError(t, SetStructValueFromString(scalarNode(v, "Flag"), "flag", "true"))
+	assert.NoError(t, SetStructValueFromString(scalarNode(v, "Count"), "count", "42"))
+	assert.NoError(t, SetStructValueFromString(scalarNode(v, "Ratio"), "ratio", "0.25"))
+	assert.NoError(t, SetStructValueFromString(scalarNode(v, "Hosts"), "hosts", "a,b"))
+
+	assert.Equal(t, scalars{Flag: true, Count: 42, Ratio: 0.25, Hosts: []string{"a", "b"}}, s)
+}
+
+func TestGetStringValueFromFieldStringList(t *testing.T) {
+	s := scalars{Hosts: []string{"a", "b"}}
+	v := reflect.ValueOf(&s).Elem()
+
+	assert.Equal(t, "a,b", GetStringValueFromField(scalarNode(v, "Hosts")))
+	assert.Equal(t, "false", GetStringValueFromField(scalarNode(v, "Flag")))
+}
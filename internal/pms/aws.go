@@ -29,13 +29,19 @@ func (p *Serializer) toPutParameters(parameters map[string]*parser.StructNode) [
 
 		if tag, ok := ToPmsTag(node); ok {
 
-			params = append(params, ssm.PutParameterInput{Name: aws.String(tag.FqName()),
+			prm := ssm.PutParameterInput{Name: aws.String(tag.FqName()),
 				Overwrite: aws.Bool(tag.Overwrite()),
 				Tier:      tag.SsmTier(p.tier),
 				Tags:      tag.SsmTags(),
 				Type:      ParameterType(node),
 				Value:     aws.String(common.GetStringValueFromField(node)),
-			})
+			}
+
+			if p.details {
+				p.putDetails(tag, &prm)
+			}
+
+			params = append(params, prm)
 
 		}
 
@@ -44,6 +50,21 @@ func (p *Serializer) toPutParameters(parameters map[string]*parser.StructNode) [
 	return params
 }
 
+// putDetails sets the key, description and pattern of the tag onto the input
+func (p *Serializer) putDetails(tag *PmsTagStruct, prm *ssm.PutParameterInput) {
+	if tag.Secure() && !tag.DefaultAccountKey() {
+		prm.KeyId = aws.String(tag.GetKeyName())
+	}
+
+	if tag.Description() != "" {
+		prm.Description = aws.String(tag.Description())
+	}
+
+	if tag.Pattern() != "" {
+		prm.AllowedPattern = aws.String(tag.Pattern())
+	}
+}
+
 // getFromAws fetches the data from the remote location.
 func (p *Serializer) getFromAws(params *ssm.GetParametersInput) (map[string]types.Parameter, []string, error) {
 
@@ -86,3 +107,30 @@ func (p *Serializer) getFromAws(params *ssm.GetParametersInput) (map[string]type
 
 	return m, resp.InvalidParameters, nil
 }
+
+// mergeJSONKeys fetches the current parameter (if any) and merges the jsonkey
+// fields onto that document so sibling keys are not clobbered.
+func (p *Serializer) mergeJSONKeys(client *ssm.Client,
+	name string, nodes []*parser.StructNode) (string, error) {
+
+	document := ""
+	resp, err := client.GetParameter(context.Background(), &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+
+	if err != nil {
+
+		var notFound *types.ParameterNotFound
+		if !errors.As(err, &notFound) {
+			return "", errors.Wrapf(err, "Failed fetch pms entry %s to merge jsonkeys", name)
+		}
+
+	} else if resp.Parameter != nil && resp.Parameter.Value != nil {
+
+		document = *resp.Parameter.Value
+
+	}
+
+	return common.MergeJSONKeys(document, nodes, "pms")
+}
@@ -0,0 +1,124 @@
+package pms
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+// maxDescribeNames is the max number of names that GetParameters accepts
+const maxDescribeNames = 10
+
+// Describe fetches the live state, including the decrypted value, of the parameters
+// by their remote names. Parameters that do not exist are not part of the result.
+func (p *Serializer) Describe(names []string) (map[string]support.RemoteState, error) {
+
+	client := ssm.NewFromConfig(p.config)
+	result := map[string]support.RemoteState{}
+
+	for i := 0; i < len(names); i += maxDescribeNames {
+		end := i + maxDescribeNames
+		if end > len(names) {
+			end = len(names)
+		}
+
+		resp, err := client.GetParameters(context.Background(), &ssm.GetParametersInput{
+			Names:          names[i:end],
+			WithDecryption: aws.Bool(true),
+		})
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to get pms-parameters %v", names[i:end])
+		}
+
+		found := []string{}
+		for _, prm := range resp.Parameters {
+			name := aws.ToString(prm.Name)
+			found = append(found, name)
+
+			result[name] = support.RemoteState{
+				Name:         name,
+				Value:        aws.ToString(prm.Value),
+				Version:      strconv.FormatInt(prm.Version, 10),
+				LastModified: aws.ToTime(prm.LastModifiedDate),
+				Type:         string(prm.Type),
+				Tags:         map[string]string{},
+			}
+		}
+
+		if len(found) == 0 {
+			continue
+		}
+
+		if err := p.describeMetadata(client, found, result); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, state := range result {
+		resp, err := client.ListTagsForResource(context.Background(), &ssm.ListTagsForResourceInput{
+			ResourceId:   aws.String(name),
+			ResourceType: types.ResourceTypeForTaggingParameter,
+		})
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to list tags on pms-parameter %s", name)
+		}
+
+		for _, tag := range resp.TagList {
+			state.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	return result, nil
+}
+
+// describeMetadata sets the tier, key, description and policies onto the already
+// fetched parameters in result.
+func (p *Serializer) describeMetadata(client *ssm.Client, names []string,
+	result map[string]support.RemoteState) error {
+
+	input := &ssm.DescribeParametersInput{
+		ParameterFilters: []types.ParameterStringFilter{{
+			Key:    aws.String("Name"),
+			Option: aws.String("Equals"),
+			Values: names,
+		}}}
+
+	for {
+		resp, err := client.DescribeParameters(context.Background(), input)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to describe pms-parameters %v", names)
+		}
+
+		for _, meta := range resp.Parameters {
+			state, ok := result[aws.ToString(meta.Name)]
+			if !ok {
+				continue
+			}
+
+			state.Tier = string(meta.Tier)
+			state.KeyID = aws.ToString(meta.KeyId)
+			state.Description = aws.ToString(meta.Description)
+
+			for _, policy := range meta.Policies {
+				state.Policies = append(state.Policies, aws.ToString(policy.PolicyText))
+			}
+
+			result[state.Name] = state
+		}
+
+		if resp.NextToken == nil {
+			break
+		}
+
+		input.NextToken = resp.NextToken
+	}
+
+	return nil
+}
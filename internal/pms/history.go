@@ -0,0 +1,137 @@
+package pms
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// History fetches all versions, oldest first, of each parameter in the node tree. The
+// result is keyed by the local field name. Secure parameters are decrypted.
+func (p *Serializer) History(node *parser.StructNode,
+	filter *support.FieldFilters) (map[string][]support.ParameterVersion, error) {
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"pms"})
+
+	client := ssm.NewFromConfig(p.config)
+	result := map[string][]support.ParameterVersion{}
+
+	for name, n := range m {
+		input := &ssm.GetParameterHistoryInput{
+			Name:           aws.String(name),
+			WithDecryption: aws.Bool(isSecure(n)),
+		}
+
+		versions := []support.ParameterVersion{}
+
+		for {
+			resp, err := client.GetParameterHistory(context.Background(), input)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to get pms history for %s", name)
+			}
+
+			for _, h := range resp.Parameters {
+				versions = append(versions, support.ParameterVersion{
+					Name:             aws.ToString(h.Name),
+					Version:          h.Version,
+					Value:            aws.ToString(h.Value),
+					Labels:           h.Labels,
+					LastModifiedDate: aws.ToTime(h.LastModifiedDate),
+					LastModifiedUser: aws.ToString(h.LastModifiedUser),
+				})
+			}
+
+			if resp.NextToken == nil {
+				break
+			}
+
+			input.NextToken = resp.NextToken
+		}
+
+		result[n.FqName] = versions
+	}
+
+	return result, nil
+}
+
+// LabelVersions attaches the label onto the latest version of each parameter in the
+// node tree. A label is moved if already attached to another version. Any field that
+// failed to be labelled is returned.
+func (p *Serializer) LabelVersions(node *parser.StructNode,
+	filter *support.FieldFilters, label string) map[string]support.FullNameField {
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"pms"})
+
+	client := ssm.NewFromConfig(p.config)
+	im := map[string]support.FullNameField{}
+
+	for name, n := range m {
+		resp, err := client.LabelParameterVersion(context.Background(), &ssm.LabelParameterVersionInput{
+			Name:   aws.String(name),
+			Labels: []string{label},
+		})
+
+		if err == nil && len(resp.InvalidLabels) > 0 {
+			err = errors.Errorf("Invalid label %v", resp.InvalidLabels)
+		}
+
+		if err != nil {
+			im[n.FqName] = p.createFullNameFieldNode(name, err, n)
+			continue
+		}
+
+		log.Debug().Str("svc", p.service).Str("method", "LabelVersions").
+			Msgf("labelled %s version %d with %s", name, resp.ParameterVersion, label)
+	}
+
+	return im
+}
+
+// Rollback reads the version of each parameter in the node tree that has the label
+// attached and writes that value as a new (latest) version. Any field that failed to
+// be rolled back is returned.
+func (p *Serializer) Rollback(node *parser.StructNode,
+	filter *support.FieldFilters, label string) map[string]support.FullNameField {
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, filter, []string{"pms"})
+
+	client := ssm.NewFromConfig(p.config)
+	im := map[string]support.FullNameField{}
+
+	for _, prm := range p.toPutParameters(m) {
+		name := *prm.Name
+		n := m[name]
+
+		resp, err := client.GetParameter(context.Background(), &ssm.GetParameterInput{
+			Name:           aws.String(name + ":" + label),
+			WithDecryption: aws.Bool(true),
+		})
+
+		if err != nil {
+			im[n.FqName] = p.createFullNameFieldNode(name, err, n)
+			continue
+		}
+
+		prm.Value = resp.Parameter.Value
+		prm.Overwrite = aws.Bool(true)
+		prm.Tags = nil
+
+		if _, err := client.PutParameter(context.Background(), &prm); err != nil {
+			im[n.FqName] = p.createFullNameFieldNode(name, err, n)
+			continue
+		}
+
+		log.Debug().Str("svc", p.service).Str("method", "Rollback").
+			Msgf("rolled back %s to version %d (%s)", name, resp.Parameter.Version, label)
+	}
+
+	return im
+}
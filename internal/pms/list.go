@@ -0,0 +1,76 @@
+package pms
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/pkg/errors"
+)
+
+// maxDeleteNames is the max number of names that DeleteParameters accepts
+const maxDeleteNames = 10
+
+// List lists the names of all parameters that begins with any of the prefixes.
+func (p *Serializer) List(prefixes ...string) ([]string, error) {
+
+	client := ssm.NewFromConfig(p.config)
+	names := []string{}
+
+	if len(prefixes) == 0 {
+		return names, nil
+	}
+
+	input := &ssm.DescribeParametersInput{
+		ParameterFilters: []types.ParameterStringFilter{{
+			Key:    aws.String("Name"),
+			Option: aws.String("BeginsWith"),
+			Values: prefixes,
+		}}}
+
+	for {
+		resp, err := client.DescribeParameters(context.Background(), input)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to list pms-parameters with prefixes %v", prefixes)
+		}
+
+		for _, prm := range resp.Parameters {
+			names = append(names, aws.ToString(prm.Name))
+		}
+
+		if resp.NextToken == nil {
+			break
+		}
+
+		input.NextToken = resp.NextToken
+	}
+
+	return names, nil
+}
+
+// DeleteNames deletes the parameters by their remote names. Parameters that do not
+// exist are ignored. Any failed deletion is returned keyed by name.
+func (p *Serializer) DeleteNames(names []string) map[string]error {
+
+	client := ssm.NewFromConfig(p.config)
+	failed := map[string]error{}
+
+	for i := 0; i < len(names); i += maxDeleteNames {
+		end := i + maxDeleteNames
+		if end > len(names) {
+			end = len(names)
+		}
+
+		_, err := client.DeleteParameters(context.Background(),
+			&ssm.DeleteParametersInput{Names: names[i:end]})
+
+		if err != nil {
+			for _, name := range names[i:end] {
+				failed[name] = err
+			}
+		}
+	}
+
+	return failed
+}
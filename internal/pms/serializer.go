@@ -2,6 +2,7 @@ package pms
 
 import (
 	"context"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -22,6 +23,8 @@ type Serializer struct {
 	service string
 	// Default tier if not specified.
 	tier types.ParameterTier
+	// details writes the key, description and pattern of the tag.
+	details bool
 }
 
 // SeDefaultTier allows for change the tier. By default the
@@ -31,6 +34,13 @@ func (p *Serializer) SeDefaultTier(tier types.ParameterTier) *Serializer {
 	return p
 }
 
+// SetWriteDetails makes Upsert write the key, description and pattern
+// of the tag. By default only the value, tier and tags are written.
+func (p *Serializer) SetWriteDetails(details bool) *Serializer {
+	p.details = details
+	return p
+}
+
 // NewFromConfig creates a repository using a existing configuration
 func NewFromConfig(config aws.Config, service string) *Serializer {
 	return &Serializer{config: config, service: service,
@@ -56,7 +66,7 @@ func (p *Serializer) Get(node *parser.StructNode,
 
 	m := map[string]*parser.StructNode{}
 	parser.NodesToParameterMap(node, m, filter, []string{"pms"})
-	paths := parser.ExtractPaths(m)
+	paths := selectorPaths(m)
 	isSecure := isSecure(node)
 
 	params := &ssm.GetParametersInput{
@@ -74,12 +84,41 @@ func (p *Serializer) Get(node *parser.StructNode,
 		return nil, err
 	}
 
-	im := p.handleInvalidRequestParameters(invalid, m, "find")
+	im := p.handleInvalidRequestParameters(withoutSelectors(invalid), m, "find")
 	err = p.populate(node, prms)
 
 	return im, err
 }
 
+// selectorPaths returns the parameter names including the version or label selector
+// (e.g. /prod/svc/name:3) when set on the tag.
+func selectorPaths(m map[string]*parser.StructNode) []string {
+	paths := []string{}
+	for _, name := range parser.ExtractPaths(m) {
+		if tag, ok := ToPmsTag(m[name]); ok {
+			name += tag.Selector()
+		}
+
+		paths = append(paths, name)
+	}
+
+	return paths
+}
+
+// withoutSelectors strips any version or label selector from the parameter names
+func withoutSelectors(names []string) []string {
+	result := make([]string, len(names))
+	for i, name := range names {
+		if idx := strings.Index(name, ":"); idx != -1 {
+			name = name[:idx]
+		}
+
+		result[i] = name
+	}
+
+	return result
+}
+
 func isSecure(node *parser.StructNode) bool {
 	if node.HasChildren() {
 		for _, n := range node.Childs {
@@ -104,7 +143,9 @@ func (p *Serializer) Upsert(node *parser.StructNode,
 	filter *support.FieldFilters) map[string]support.FullNameField {
 
 	m := map[string]*parser.StructNode{}
+	groups := map[string][]*parser.StructNode{}
 	parser.NodesToParameterMap(node, m, filter, []string{"pms"})
+	parser.NodesToParameterGroups(node, groups, filter, []string{"pms"})
 
 	im := map[string]support.FullNameField{}
 	if len(m) == 0 {
@@ -115,6 +156,19 @@ func (p *Serializer) Upsert(node *parser.StructNode,
 
 	for _, prm := range params {
 
+		if group := groups[*prm.Name]; common.IsJSONKeyGroup(group, "pms") {
+
+			doc, err := p.mergeJSONKeys(client, *prm.Name, group)
+			if err != nil {
+
+				im[group[0].FqName] = p.createFullNameFieldNode(*prm.Name, err, m[*prm.Name])
+				continue
+
+			}
+
+			prm.Value = aws.String(doc)
+		}
+
 		tags := prm.Tags
 		prm.Tags = nil
 
@@ -204,7 +258,13 @@ func (p *Serializer) populate(node *parser.StructNode, params map[string]types.P
 
 		if val, ok := params[tag.GetFullName()]; ok {
 
-			if tag.GetFullName() != "" {
+			if key := common.JSONKey(node, "pms"); key != "" {
+
+				if _, err := common.SetStructValueFromJSONKey(node, *val.Name, *val.Value, key); err != nil {
+					log.Warn().Msgf("failed to read jsonkey %s from %s error: %v", key, *val.Name, err)
+				}
+
+			} else if tag.GetFullName() != "" {
 
 				common.SetStructValueFromString(node, *val.Name, *val.Value)
 
@@ -389,3 +389,72 @@ func TestMarshalSubStructAsJSON(t *testing.T) {
 }
 
 // cSpell:enable
+
+func TestSelectorFromVersionAndLabelTags(t *testing.T) {
+	type Test struct {
+		Latest  string `pms:"latest"`
+		Version string `pms:"version, version=3"`
+		Label   string `pms:"label, label=stable"`
+	}
+
+	var test Test
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("pms", NewTagParser()).
+		Parse(reflect.ValueOf(&test))
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	m := map[string]*parser.StructNode{}
+	parser.NodesToParameterMap(node, m, support.NewFilters(), []string{"pms"})
+
+	assert.ElementsMatch(t, []string{
+		"/" + stage + "/test-service/latest",
+		"/" + stage + "/test-service/version:3",
+		"/" + stage + "/test-service/label:stable",
+	}, selectorPaths(m))
+
+	assert.Equal(t, []string{"/" + stage + "/test-service/label"},
+		withoutSelectors([]string{"/" + stage + "/test-service/label:stable"}))
+}
+
+func TestLabelAndRollback(t *testing.T) {
+	if scope != "rw" {
+		return
+	}
+
+	type Test struct {
+		Name string `pms:"rollback"`
+	}
+
+	test := Test{Name: "good"}
+	node, err := parser.New("test-service", stage, "").
+		RegisterTagParser("pms", NewTagParser()).
+		Parse(reflect.ValueOf(&test))
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	pmsRepository, err := New("test-service")
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	assert.Equal(t, 0, len(pmsRepository.Upsert(node, support.NewFilters())))
+	assert.Equal(t, 0, len(pmsRepository.LabelVersions(node, support.NewFilters(), "stable")))
+
+	test.Name = "bad"
+	assert.Equal(t, 0, len(pmsRepository.Upsert(node, support.NewFilters())))
+	assert.Equal(t, 0, len(pmsRepository.Rollback(node, support.NewFilters(), "stable")))
+
+	_, err = pmsRepository.Get(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "good", test.Name)
+
+	history, err := pmsRepository.History(node, support.NewFilters())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(history["Name"]))
+	assert.Equal(t, []string{"stable"}, history["Name"][0].Labels)
+}
@@ -20,6 +20,9 @@ func NewTagParser() parser.TagParser {
 			"pattern",
 			"overwrite",
 			"tier",
+			"jsonkey",
+			"version",
+			"label",
 		}),
 	}
 }
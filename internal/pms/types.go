@@ -76,6 +76,10 @@ type PmsTag interface {
 	SsmTier(defaultTier types.ParameterTier) types.ParameterTier
 	Pattern() string
 	SsmTags() []types.Tag
+	JSONKey() string
+	Version() string
+	Label() string
+	Selector() string
 }
 
 // PmsTagStruct is for AWS parameter store
@@ -141,6 +145,34 @@ func (t *PmsTagStruct) Overwrite() bool {
 // Pattern returns a optional regular expression to validate the parameter value.
 func (t *PmsTagStruct) Pattern() string { return t.StructTagImpl.Named["pattern"] }
 
+// JSONKey is the top level key or JSON pointer (when starting with a slash) that
+// this field reads out of the parameter JSON document. Several fields may share the
+// same parameter and read different keys. The parameter is fetched only once.
+func (t *PmsTagStruct) JSONKey() string { return t.StructTagImpl.Named["jsonkey"] }
+
+// Version is the parameter version to read. When set, the field is pinned to that
+// version instead of the latest.
+func (t *PmsTagStruct) Version() string { return t.StructTagImpl.Named["version"] }
+
+// Label is the parameter label to read. When set, the field reads the version that
+// has the label attached instead of the latest.
+func (t *PmsTagStruct) Label() string { return t.StructTagImpl.Named["label"] }
+
+// Selector returns the GetParameters selector such as :3 for a version or :label for
+// a label. If neither is set an empty string is returned. Version takes precedence
+// over label.
+func (t *PmsTagStruct) Selector() string {
+	if version := t.Version(); version != "" {
+		return ":" + version
+	}
+
+	if label := t.Label(); label != "" {
+		return ":" + label
+	}
+
+	return ""
+}
+
 // Description returns a  description describing the parameter (if any).
 func (t *PmsTagStruct) Description() string { return t.StructTagImpl.Named["description"] }
 
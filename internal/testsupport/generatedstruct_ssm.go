@@ -0,0 +1,227 @@
+// Code generated by "ssmgen -type GeneratedStruct"; DO NOT EDIT.
+
+package testsupport
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mariotoffia/ssm/parser"
+)
+
+// SsmFields implements parser.Generated.
+func (v *GeneratedStruct) SsmFields() []parser.GeneratedField {
+	return []parser.GeneratedField{
+		{Name: "Name", Tag: `pms:"test, prefix=simple,tag1=nanna banna panna"`, Value: reflect.ValueOf(&v.Name).Elem()},
+		{Name: "Count", Tag: `pms:"count, description=number of items"`, Value: reflect.ValueOf(&v.Count).Elem()},
+		{Name: "Small", Tag: `pms:"small"`, Value: reflect.ValueOf(&v.Small).Elem()},
+		{Name: "Port", Tag: `pms:"port"`, Value: reflect.ValueOf(&v.Port).Elem()},
+		{Name: "Enabled", Tag: `pms:"enabled"`, Value: reflect.ValueOf(&v.Enabled).Elem()},
+		{Name: "Ratio", Tag: `pms:"ratio"`, Value: reflect.ValueOf(&v.Ratio).Elem()},
+		{Name: "Weight", Tag: `pms:"weight, tier=adv"`, Value: reflect.ValueOf(&v.Weight).Elem()},
+		{Name: "Hosts", Tag: `pms:"hosts"`, Value: reflect.ValueOf(&v.Hosts).Elem()},
+		{Name: "Password", Tag: `pms:"password, keyid=default"`, Value: reflect.ValueOf(&v.Password).Elem()},
+		{Name: "Unknown", Tag: `pms:"unknown"`, Value: reflect.ValueOf(&v.Unknown).Elem(), Reflect: true},
+		{Name: "Plain", Value: reflect.ValueOf(&v.Plain).Elem()},
+		{Name: "Sub", Value: reflect.ValueOf(&v.Sub).Elem(),
+			Fields: []parser.GeneratedField{
+				{Name: "Host", Tag: `pms:"host"`, Value: reflect.ValueOf(&v.Sub.Host).Elem()},
+				{Name: "Deep", Value: reflect.ValueOf(&v.Sub.Deep).Elem(),
+					Fields: []parser.GeneratedField{
+						{Name: "Level", Tag: `pms:"level"`, Value: reflect.ValueOf(&v.Sub.Deep.Level).Elem()},
+					}},
+			}},
+		{Name: "Inline", Value: reflect.ValueOf(&v.Inline).Elem(),
+			Fields: []parser.GeneratedField{
+				{Name: "Apa", Tag: `asm:"ext"`, Value: reflect.ValueOf(&v.Inline.Apa).Elem()},
+				{Name: "Nu", Tag: `asm:"myname, prefix=/global"`, Value: reflect.ValueOf(&v.Inline.Nu).Elem()},
+			}},
+		{Name: "Settings", Tag: `pms:"settings"`, Value: reflect.ValueOf(&v.Settings).Elem(),
+			Fields: []parser.GeneratedField{
+				{Name: "BatchSize", Tag: `json:"batchsize"`, Value: reflect.ValueOf(&v.Settings.BatchSize).Elem()},
+				{Name: "Signer", Tag: `json:"signer,omitempty"`, Value: reflect.ValueOf(&v.Settings.Signer).Elem()},
+			}},
+		{Name: "Rds", Tag: `asm:"rds, strkey=password"`, Value: reflect.ValueOf(&v.Rds).Elem(),
+			Fields: []parser.GeneratedField{
+				{Name: "User", Tag: `json:"user"`, Value: reflect.ValueOf(&v.Rds.User).Elem()},
+				{Name: "Password", Tag: `json:"password"`, Value: reflect.ValueOf(&v.Rds.Password).Elem()},
+				{Name: "Port", Tag: `json:"port"`, Value: reflect.ValueOf(&v.Rds.Port).Elem()},
+			}},
+		{Name: "User", Tag: `asm:"jsonrds, jsonkey=username"`, Value: reflect.ValueOf(&v.User).Elem()},
+		{Name: "Timeout", Tag: `asm:"jsonrds, jsonkey=timeout"`, Value: reflect.ValueOf(&v.Timeout).Elem()},
+		{Name: "Rotating", Tag: `asm:"rotating"`, Value: reflect.ValueOf(&v.Rotating).Elem(), Reflect: true},
+	}
+}
+
+// SsmPopulate implements parser.Generated.
+func (v *GeneratedStruct) SsmPopulate(field string, value string) (bool, error) {
+	switch field {
+	case "Name":
+		v.Name = value
+	case "Count":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Count = int(i)
+	case "Small":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Small = int8(i)
+	case "Port":
+		u, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Port = uint16(u)
+	case "Enabled":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return true, err
+		}
+		v.Enabled = b
+	case "Ratio":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Ratio = float32(f)
+	case "Weight":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Weight = f
+	case "Hosts":
+		v.Hosts = strings.Split(value, ",")
+	case "Password":
+		v.Password = value
+	case "Plain":
+		v.Plain = value
+	case "Sub":
+		return true, json.Unmarshal([]byte(value), &v.Sub)
+	case "Sub.Host":
+		v.Sub.Host = value
+	case "Sub.Deep":
+		return true, json.Unmarshal([]byte(value), &v.Sub.Deep)
+	case "Sub.Deep.Level":
+		u, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Sub.Deep.Level = uint(u)
+	case "Inline":
+		return true, json.Unmarshal([]byte(value), &v.Inline)
+	case "Inline.Apa":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Inline.Apa = int(i)
+	case "Inline.Nu":
+		v.Inline.Nu = value
+	case "Settings":
+		return true, json.Unmarshal([]byte(value), &v.Settings)
+	case "Settings.BatchSize":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Settings.BatchSize = int(i)
+	case "Settings.Signer":
+		v.Settings.Signer = value
+	case "Rds":
+		return true, json.Unmarshal([]byte(value), &v.Rds)
+	case "Rds.User":
+		v.Rds.User = value
+	case "Rds.Password":
+		v.Rds.Password = value
+	case "Rds.Port":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Rds.Port = int(i)
+	case "User":
+		v.User = value
+	case "Timeout":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return true, err
+		}
+		v.Timeout = int(i)
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// SsmMarshal implements parser.Generated.
+func (v *GeneratedStruct) SsmMarshal(field string) (string, bool) {
+	switch field {
+	case "Name":
+		return v.Name, true
+	case "Count":
+		return strconv.FormatInt(int64(v.Count), 10), true
+	case "Small":
+		return strconv.FormatInt(int64(v.Small), 10), true
+	case "Port":
+		return strconv.FormatUint(uint64(v.Port), 10), true
+	case "Enabled":
+		return strconv.FormatBool(v.Enabled), true
+	case "Ratio":
+		return strconv.FormatFloat(float64(v.Ratio), 'f', -1, 32), true
+	case "Weight":
+		return strconv.FormatFloat(v.Weight, 'f', -1, 64), true
+	case "Hosts":
+		return strings.Join(v.Hosts, ","), true
+	case "Password":
+		return v.Password, true
+	case "Plain":
+		return v.Plain, true
+	case "Sub":
+		data, _ := json.Marshal(&v.Sub)
+		return string(data), true
+	case "Sub.Host":
+		return v.Sub.Host, true
+	case "Sub.Deep":
+		data, _ := json.Marshal(&v.Sub.Deep)
+		return string(data), true
+	case "Sub.Deep.Level":
+		return strconv.FormatUint(uint64(v.Sub.Deep.Level), 10), true
+	case "Inline":
+		data, _ := json.Marshal(&v.Inline)
+		return string(data), true
+	case "Inline.Apa":
+		return strconv.FormatInt(int64(v.Inline.Apa), 10), true
+	case "Inline.Nu":
+		return v.Inline.Nu, true
+	case "Settings":
+		data, _ := json.Marshal(&v.Settings)
+		return string(data), true
+	case "Settings.BatchSize":
+		return strconv.FormatInt(int64(v.Settings.BatchSize), 10), true
+	case "Settings.Signer":
+		return v.Settings.Signer, true
+	case "Rds":
+		data, _ := json.Marshal(&v.Rds)
+		return string(data), true
+	case "Rds.User":
+		return v.Rds.User, true
+	case "Rds.Password":
+		return v.Rds.Password, true
+	case "Rds.Port":
+		return strconv.FormatInt(int64(v.Rds.Port), 10), true
+	case "User":
+		return v.User, true
+	case "Timeout":
+		return strconv.FormatInt(int64(v.Timeout), 10), true
+	}
+
+	return "", false
+}
@@ -44,6 +44,9 @@ func Secrets(stage string) []secretsmanager.CreateSecretInput {
 		{Name: aws.String(fmt.Sprintf("/%s/test-service/bubbibobbo", stage)),
 			SecretString:       aws.String(`{"user":"gurkaburka","timeout":998}`),
 			ClientRequestToken: aws.String(uuid.New().String())},
+		{Name: aws.String(fmt.Sprintf("/%s/test-service/rds", stage)),
+			SecretString:       aws.String(`{"username":"admin","password":"s3cr3t","host":"db.local","port":5432}`),
+			ClientRequestToken: aws.String(uuid.New().String())},
 	}
 }
 
@@ -61,6 +61,14 @@ type MyDbServiceConfigAsm struct {
 	} `asm:"bubbibobbo, strkey=password"`
 }
 
+// JSONKeyAsmStruct reads separate keys out of a single JSON secret
+type JSONKeyAsmStruct struct {
+	User     string `asm:"rds, jsonkey=username"`
+	Password string `asm:"rds, jsonkey=password"`
+	Host     string `asm:"rds, jsonkey=/host"`
+	Port     int    `asm:"rds, jsonkey=port"`
+}
+
 // MyContextPostgresSQL demo context
 type MyContextPostgresSQL struct {
 	DbCtx    support.SecretsManagerRDSPostgreSQLRotationSingleUser `asm:"dbctx, strkey=password"`
@@ -69,3 +77,49 @@ type MyContextPostgresSQL struct {
 		Signer    string `json:"signer,omitempty"`
 	} `pms:"settings"`
 }
+
+//go:generate go run ../../cmd/ssmgen -type GeneratedStruct
+
+// GeneratedStruct has code generated by ssmgen and is used by the tests that verifies
+// that the generated code behaves as when using reflection.
+type GeneratedStruct struct {
+	Name     string   `pms:"test, prefix=simple,tag1=nanna banna panna"`
+	Count    int      `pms:"count, description=number of items"`
+	Small    int8     `pms:"small"`
+	Port     uint16   `pms:"port"`
+	Enabled  bool     `pms:"enabled"`
+	Ratio    float32  `pms:"ratio"`
+	Weight   float64  `pms:"weight, tier=adv"`
+	Hosts    []string `pms:"hosts"`
+	Password string   `pms:"password, keyid=default"`
+	Unknown  int16    `pms:"unknown"`
+	Plain    string
+	Sub      GeneratedSub
+	Inline   struct {
+		Apa int    `asm:"ext"`
+		Nu  string `asm:"myname, prefix=/global"`
+	}
+	Settings struct {
+		BatchSize int    `json:"batchsize"`
+		Signer    string `json:"signer,omitempty"`
+	} `pms:"settings"`
+	Rds      GeneratedConnection    `asm:"rds, strkey=password"`
+	User     string                 `asm:"jsonrds, jsonkey=username"`
+	Timeout  int                    `asm:"jsonrds, jsonkey=timeout"`
+	Rotating support.RotatingSecret `asm:"rotating"`
+}
+
+// GeneratedSub is a nested struct of GeneratedStruct
+type GeneratedSub struct {
+	Host string `pms:"host"`
+	Deep struct {
+		Level uint `pms:"level"`
+	}
+}
+
+// GeneratedConnection is a JSON secret in GeneratedStruct
+type GeneratedConnection struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Port     int    `json:"port"`
+}
@@ -0,0 +1,150 @@
+// Package kubernetes renders External Secrets Operator manifests out of a report.Report.
+// All parameters and secrets are mapped to keys in a single Kubernetes Secret using one
+// ExternalSecret that reads from a ParameterStore and a SecretsManager SecretStore.
+package kubernetes
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// APIVersion is the External Secrets Operator api version of the manifests
+	APIVersion = "external-secrets.io/v1beta1"
+	// KindExternalSecret is the kind of a ExternalSecret
+	KindExternalSecret = "ExternalSecret"
+	// KindSecretStore is the kind of a namespaced SecretStore
+	KindSecretStore = "SecretStore"
+	// KindClusterSecretStore is the kind of a cluster wide SecretStore
+	KindClusterSecretStore = "ClusterSecretStore"
+	// ServiceParameterStore is the AWS provider service for parameter store
+	ServiceParameterStore = "ParameterStore"
+	// ServiceSecretsManager is the AWS provider service for secrets manager
+	ServiceSecretsManager = "SecretsManager"
+)
+
+// Manifests is the rendered manifests
+type Manifests struct {
+	// SecretStores is the stores, only rendered when Options.Region is set
+	SecretStores []SecretStore
+	// ExternalSecret is the external secret that maps all parameters
+	ExternalSecret ExternalSecret
+	// Skipped is the report parameters that could not be rendered, keyed by full
+	// name, with the reason as value.
+	Skipped map[string]string
+}
+
+// Metadata is the kubernetes object metadata
+type Metadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// SecretStore is a SecretStore, or ClusterSecretStore, with the AWS provider
+type SecretStore struct {
+	APIVersion string          `yaml:"apiVersion"`
+	Kind       string          `yaml:"kind"`
+	Metadata   Metadata        `yaml:"metadata"`
+	Spec       SecretStoreSpec `yaml:"spec"`
+}
+
+// SecretStoreSpec is the store provider
+type SecretStoreSpec struct {
+	Provider Provider `yaml:"provider"`
+}
+
+// Provider holds the AWS provider
+type Provider struct {
+	AWS AWSProvider `yaml:"aws"`
+}
+
+// AWSProvider is the AWS service and region to read from
+type AWSProvider struct {
+	Service string   `yaml:"service"`
+	Region  string   `yaml:"region"`
+	Auth    *AWSAuth `yaml:"auth,omitempty"`
+}
+
+// AWSAuth authenticates using a service account (IRSA)
+type AWSAuth struct {
+	JWT JWTAuth `yaml:"jwt"`
+}
+
+// JWTAuth references the service account
+type JWTAuth struct {
+	ServiceAccountRef ServiceAccountRef `yaml:"serviceAccountRef"`
+}
+
+// ServiceAccountRef is the name of a service account
+type ServiceAccountRef struct {
+	Name string `yaml:"name"`
+}
+
+// ExternalSecret maps remote parameters to keys in a Kubernetes Secret
+type ExternalSecret struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   Metadata           `yaml:"metadata"`
+	Spec       ExternalSecretSpec `yaml:"spec"`
+}
+
+// ExternalSecretSpec is the default store, the target secret and the keys
+type ExternalSecretSpec struct {
+	RefreshInterval string   `yaml:"refreshInterval"`
+	SecretStoreRef  StoreRef `yaml:"secretStoreRef"`
+	Target          Target   `yaml:"target"`
+	Data            []Data   `yaml:"data"`
+}
+
+// StoreRef references a SecretStore or ClusterSecretStore
+type StoreRef struct {
+	Name string `yaml:"name"`
+	Kind string `yaml:"kind"`
+}
+
+// Target is the Kubernetes Secret to create
+type Target struct {
+	Name           string `yaml:"name"`
+	CreationPolicy string `yaml:"creationPolicy"`
+}
+
+// Data is a single key in the target secret
+type Data struct {
+	SecretKey string     `yaml:"secretKey"`
+	SourceRef *SourceRef `yaml:"sourceRef,omitempty"`
+	RemoteRef RemoteRef  `yaml:"remoteRef"`
+}
+
+// SourceRef overrides the store for a single key
+type SourceRef struct {
+	StoreRef StoreRef `yaml:"storeRef"`
+}
+
+// RemoteRef is the remote name and, for JSON documents, the property to extract
+type RemoteRef struct {
+	Key      string `yaml:"key"`
+	Property string `yaml:"property,omitempty"`
+}
+
+// YAML renders the stores followed by the external secret as a multi document YAML
+// indented with two spaces.
+func (m *Manifests) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	for i := range m.SecretStores {
+		if err := encoder.Encode(&m.SecretStores[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Encode(&m.ExternalSecret); err != nil {
+		return nil, err
+	}
+
+	err := encoder.Close()
+	return buf.Bytes(), err
+}
@@ -0,0 +1,220 @@
+package kubernetes
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/pkg/errors"
+)
+
+// DefaultRefreshInterval is the refresh interval when not set in Options
+const DefaultRefreshInterval = "1h"
+
+// Options controls how the manifests are rendered
+type Options struct {
+	// Name is the name of the ExternalSecret and the target Kubernetes Secret (mandatory)
+	Name string
+	// Namespace is the namespace of the manifests, empty for the default namespace
+	Namespace string
+	// StoreKind is KindSecretStore (default) or KindClusterSecretStore
+	StoreKind string
+	// ParameterStore is the name of the parameter store SecretStore. Default is the
+	// Name suffixed with -parameterstore.
+	ParameterStore string
+	// SecretsManager is the name of the secrets manager SecretStore. Default is the
+	// Name suffixed with -secretsmanager.
+	SecretsManager string
+	// Region, when set, renders the SecretStore manifests for the region
+	Region string
+	// ServiceAccount, when set, authenticates the SecretStores using the service account
+	ServiceAccount string
+	// RefreshInterval is how often the values are read. Default is DefaultRefreshInterval.
+	RefreshInterval string
+}
+
+// Render renders a single ExternalSecret where each parameter, and secret, is a key in
+// the target secret. The key is the last segment of the name, e.g. /prod/svc/db-host
+// becomes db-host. Parameters that reads a jsonkey, such as the RDS templates in
+// support, are extracted one key per jsonkey using the remoteRef property. If two
+// keys renders the same name, a hash of the full name is appended.
+//
+// Secrets manager secrets reads from its own store using a sourceRef when parameter
+// store parameters are in the report as well. Parameter types not known by the
+// renderer are skipped.
+func Render(rpt *report.Report, opts Options) (*Manifests, error) {
+	if opts.Name == "" {
+		return nil, errors.New("A name is required to render an ExternalSecret")
+	}
+
+	if opts.StoreKind == "" {
+		opts.StoreKind = KindSecretStore
+	}
+	if opts.ParameterStore == "" {
+		opts.ParameterStore = opts.Name + "-parameterstore"
+	}
+	if opts.SecretsManager == "" {
+		opts.SecretsManager = opts.Name + "-secretsmanager"
+	}
+	if opts.RefreshInterval == "" {
+		opts.RefreshInterval = DefaultRefreshInterval
+	}
+
+	pmsRef := StoreRef{Name: opts.ParameterStore, Kind: opts.StoreKind}
+	asmRef := StoreRef{Name: opts.SecretsManager, Kind: opts.StoreKind}
+
+	defaultRef := asmRef
+	for _, prm := range rpt.Parameters {
+		if prm.Type == report.ParameterStore {
+			defaultRef = pmsRef
+			break
+		}
+	}
+
+	m := &Manifests{Skipped: map[string]string{}}
+	m.ExternalSecret = ExternalSecret{
+		APIVersion: APIVersion,
+		Kind:       KindExternalSecret,
+		Metadata:   Metadata{Name: opts.Name, Namespace: opts.Namespace},
+		Spec: ExternalSecretSpec{
+			RefreshInterval: opts.RefreshInterval,
+			SecretStoreRef:  defaultRef,
+			Target:          Target{Name: opts.Name, CreationPolicy: "Owner"},
+			Data:            []Data{},
+		},
+	}
+
+	keys := map[string]string{}
+	stores := map[string]bool{}
+
+	for i := range rpt.Parameters {
+		prm := &rpt.Parameters[i]
+
+		var (
+			ref      StoreRef
+			jsonkeys []string
+		)
+
+		switch prm.Type {
+		case report.ParameterStore:
+			details, _ := prm.PmsDetails()
+			ref, jsonkeys = pmsRef, details.JSONKeys
+		case report.SecretsManager:
+			details, _ := prm.AsmDetails()
+			ref, jsonkeys = asmRef, details.JSONKeys
+		default:
+			m.Skipped[prm.Name] = fmt.Sprintf("unsupported parameter type %s", prm.Type)
+			continue
+		}
+
+		stores[ref.Name] = true
+
+		var source *SourceRef
+		if ref != defaultRef {
+			source = &SourceRef{StoreRef: ref}
+		}
+
+		if len(jsonkeys) == 0 {
+			m.ExternalSecret.Spec.Data = append(m.ExternalSecret.Spec.Data, Data{
+				SecretKey: SecretKey(lastSegment(prm.Name), prm.Name, keys),
+				SourceRef: source,
+				RemoteRef: RemoteRef{Key: prm.Name},
+			})
+
+			continue
+		}
+
+		for _, key := range jsonkeys {
+			tokens := common.JSONKeyTokens(key)
+
+			m.ExternalSecret.Spec.Data = append(m.ExternalSecret.Spec.Data, Data{
+				SecretKey: SecretKey(tokens[len(tokens)-1], prm.Name+"#"+key, keys),
+				SourceRef: source,
+				RemoteRef: RemoteRef{Key: prm.Name, Property: Property(key)},
+			})
+		}
+	}
+
+	if opts.Region != "" {
+		for _, s := range []struct {
+			name    string
+			service string
+		}{
+			{opts.ParameterStore, ServiceParameterStore},
+			{opts.SecretsManager, ServiceSecretsManager},
+		} {
+			if stores[s.name] {
+				m.SecretStores = append(m.SecretStores, secretStore(s.name, s.service, opts))
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func secretStore(name string, service string, opts Options) SecretStore {
+	store := SecretStore{
+		APIVersion: APIVersion,
+		Kind:       opts.StoreKind,
+		Metadata:   Metadata{Name: name},
+		Spec:       SecretStoreSpec{Provider: Provider{AWS: AWSProvider{Service: service, Region: opts.Region}}},
+	}
+
+	if opts.StoreKind != KindClusterSecretStore {
+		store.Metadata.Namespace = opts.Namespace
+	}
+
+	if opts.ServiceAccount != "" {
+		store.Spec.Provider.AWS.Auth = &AWSAuth{JWT: JWTAuth{
+			ServiceAccountRef: ServiceAccountRef{Name: opts.ServiceAccount},
+		}}
+	}
+
+	return store
+}
+
+// Property converts a jsonkey into a remoteRef property, i.e. a gjson path. A JSON
+// pointer such as /db/host becomes db.host. Special characters are escaped.
+func Property(jsonkey string) string {
+	tokens := common.JSONKeyTokens(jsonkey)
+
+	escaper := strings.NewReplacer(`\`, `\\`, ".", `\.`, "*", `\*`, "?", `\?`)
+	for i := range tokens {
+		tokens[i] = escaper.Replace(tokens[i])
+	}
+
+	return strings.Join(tokens, ".")
+}
+
+// SecretKey derives a valid secret key out of name, i.e. only alphanumerics, dash,
+// underscore and dot. If the key is already used by another id, a hash of the id is
+// appended. The keys map holds the ids keyed by the rendered key.
+func SecretKey(name string, id string, keys map[string]string) string {
+	key := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.", r)) {
+			return '-'
+		}
+
+		return r
+	}, name)
+
+	if key == "" {
+		key = "value"
+	}
+
+	if existing, ok := keys[key]; ok && existing != id {
+		h := fnv.New32a()
+		h.Write([]byte(id))
+		key = fmt.Sprintf("%s-%08x", key, h.Sum32())
+	}
+
+	keys[key] = id
+	return key
+}
+
+func lastSegment(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *report.Report {
+	return &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/test-service/db-host", Value: "localhost",
+			ValueType: "String", Details: report.PmsParameterDetails{Tier: "Standard"}},
+		{Type: report.SecretsManager, Name: "/prod/test-service/rds", ValueType: "SecureString",
+			Details: report.AsmParameterDetails{JSONKeys: []string{"username", "password", "/db/host.name"}}},
+		{Type: report.SecretsManager, Name: "/prod/other/db-host", ValueType: "SecureString",
+			Details: report.AsmParameterDetails{}},
+		{Type: "consul", Name: "/prod/test-service/flag"},
+	}}
+}
+
+func TestRenderMapsEachParameterToAKey(t *testing.T) {
+	m, err := Render(testReport(), Options{Name: "test-service", Namespace: "apps"})
+	assert.Equal(t, nil, err)
+
+	es := m.ExternalSecret
+	assert.Equal(t, APIVersion, es.APIVersion)
+	assert.Equal(t, Metadata{Name: "test-service", Namespace: "apps"}, es.Metadata)
+	assert.Equal(t, StoreRef{Name: "test-service-parameterstore", Kind: KindSecretStore}, es.Spec.SecretStoreRef)
+	assert.Equal(t, Target{Name: "test-service", CreationPolicy: "Owner"}, es.Spec.Target)
+	assert.Equal(t, DefaultRefreshInterval, es.Spec.RefreshInterval)
+
+	assert.Equal(t, 5, len(es.Spec.Data))
+	assert.Equal(t, Data{SecretKey: "db-host", RemoteRef: RemoteRef{Key: "/prod/test-service/db-host"}}, es.Spec.Data[0])
+
+	asmRef := &SourceRef{StoreRef: StoreRef{Name: "test-service-secretsmanager", Kind: KindSecretStore}}
+	assert.Equal(t, Data{SecretKey: "username", SourceRef: asmRef,
+		RemoteRef: RemoteRef{Key: "/prod/test-service/rds", Property: "username"}}, es.Spec.Data[1])
+	assert.Equal(t, Data{SecretKey: "host.name", SourceRef: asmRef,
+		RemoteRef: RemoteRef{Key: "/prod/test-service/rds", Property: `db.host\.name`}}, es.Spec.Data[3])
+
+	assert.Contains(t, m.Skipped, "/prod/test-service/flag")
+}
+
+func TestRenderCollidingKeysGetsHash(t *testing.T) {
+	m, err := Render(testReport(), Options{Name: "test-service"})
+	assert.Equal(t, nil, err)
+
+	data := m.ExternalSecret.Spec.Data[4]
+	assert.Regexp(t, `^db-host-[0-9a-f]{8}$`, data.SecretKey)
+	assert.Equal(t, "/prod/other/db-host", data.RemoteRef.Key)
+}
+
+func TestRenderOnlySecretsUsesSecretsManagerStore(t *testing.T) {
+	rpt := &report.Report{Parameters: testReport().Parameters[1:3]}
+
+	m, err := Render(rpt, Options{Name: "svc", StoreKind: KindClusterSecretStore, SecretsManager: "aws"})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, StoreRef{Name: "aws", Kind: KindClusterSecretStore}, m.ExternalSecret.Spec.SecretStoreRef)
+	for _, data := range m.ExternalSecret.Spec.Data {
+		assert.Nil(t, data.SourceRef)
+	}
+}
+
+func TestRenderSecretStores(t *testing.T) {
+	m, err := Render(testReport(), Options{Name: "svc", Namespace: "apps", Region: "eu-west-1", ServiceAccount: "svc-sa"})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 2, len(m.SecretStores))
+	assert.Equal(t, Metadata{Name: "svc-parameterstore", Namespace: "apps"}, m.SecretStores[0].Metadata)
+	assert.Equal(t, ServiceParameterStore, m.SecretStores[0].Spec.Provider.AWS.Service)
+	assert.Equal(t, ServiceSecretsManager, m.SecretStores[1].Spec.Provider.AWS.Service)
+	assert.Equal(t, "svc-sa", m.SecretStores[1].Spec.Provider.AWS.Auth.JWT.ServiceAccountRef.Name)
+}
+
+func TestRenderRequiresName(t *testing.T) {
+	_, err := Render(testReport(), Options{})
+	assert.Error(t, err)
+}
+
+func TestYAML(t *testing.T) {
+	m, err := Render(testReport(), Options{Name: "svc", Region: "eu-west-1"})
+	assert.Equal(t, nil, err)
+
+	data, err := m.YAML()
+	assert.Equal(t, nil, err)
+
+	yml := string(data)
+	assert.Contains(t, yml, "kind: SecretStore\n")
+	assert.Contains(t, yml, "---\n")
+	assert.Contains(t, yml, "kind: ExternalSecret\n")
+	assert.Contains(t, yml, "  data:\n    - secretKey: db-host\n      remoteRef:\n        key: /prod/test-service/db-host\n")
+	assert.Contains(t, yml, "      property: username\n")
+	assert.NotContains(t, yml, "namespace:")
+}
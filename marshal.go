@@ -47,13 +47,17 @@ func (s *Serializer) marshal(v interface{},
 
 	var invalid map[string]support.FullNameField
 
+	if s.validate {
+		invalid, filter = s.validateSchemas(node, filter, usage)
+	}
+
 	if _, found := find(usage, UsePms); found {
 		pmsRepository, err := s.getAndConfigurePms()
 		if err != nil {
 			return map[string]support.FullNameField{"": {Error: err}}, nil
 		}
 
-		invalid = pmsRepository.Upsert(node, filter)
+		invalid = mergeInvalid(invalid, pmsRepository.Upsert(node, filter))
 	}
 
 	if _, found := find(usage, UseAsm); found {
@@ -62,16 +66,24 @@ func (s *Serializer) marshal(v interface{},
 			return map[string]support.FullNameField{"": {Error: err}}, nil
 		}
 
-		invalid2 := asmRepository.Upsert(node, filter)
-		if invalid == nil && len(invalid2) > 0 {
-			invalid = map[string]support.FullNameField{}
-		}
-
 		// Merge field errors from ASM with PMS errors
-		for key, value := range invalid2 {
-			invalid[key] = value
-		}
+		invalid = mergeInvalid(invalid, asmRepository.Upsert(node, filter))
 	}
 
 	return invalid, node
 }
+
+// mergeInvalid adds the fields in other to invalid. If invalid is nil, other is returned.
+func mergeInvalid(invalid map[string]support.FullNameField,
+	other map[string]support.FullNameField) map[string]support.FullNameField {
+
+	if invalid == nil {
+		return other
+	}
+
+	for key, value := range other {
+		invalid[key] = value
+	}
+
+	return invalid
+}
@@ -0,0 +1,301 @@
+package ssm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/mariotoffia/ssm/snapshot"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+// PutParameters upserts the parameters without any go struct. Each parameter is written
+// using its full name, value, value type, description, key, tags and, for parameter store,
+// tier and pattern. Secure parameters without a key use the account default key and
+// StringList values are comma separated. It returns the parameters that failed to be
+// written keyed by the remote name.
+func (s *Serializer) PutParameters(params ...report.Parameter) (map[string]support.FullNameField, error) {
+	invalid := map[string]support.FullNameField{}
+	supported := []report.Parameter{}
+	usage := map[Usage]bool{}
+
+	for _, prm := range params {
+		if err := validateParameter(&prm); err != nil {
+			invalid[prm.Name] = support.FullNameField{RemoteName: prm.Name, Error: err}
+			continue
+		}
+
+		supported = append(supported, prm)
+		usage[parameterUsage(&prm)] = true
+	}
+
+	if len(supported) == 0 {
+		return invalid, nil
+	}
+
+	node, err := s.parametersNode(supported)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range supported {
+		patchTag(&node.Childs[i], &supported[i])
+	}
+
+	filter := support.NewFilters()
+
+	if usage[UsePms] {
+		pmsRepository, err := s.getAndConfigurePms()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range pmsRepository.SetWriteDetails(true).Upsert(node, filter) {
+			invalid[value.RemoteName] = value
+		}
+	}
+
+	if usage[UseAsm] {
+		asmRepository, err := s.getAndConfigureAsm()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range asmRepository.Upsert(node, filter) {
+			invalid[value.RemoteName] = value
+		}
+	}
+
+	return invalid, nil
+}
+
+// DescribeReport fills each parameter in the report with its live state, as
+// RemoteReportWithOpts does, but without any go struct. This is used on reports read
+// from file or parameters created out of names. If redact is set, all secure values are
+// replaced with report.Redacted.
+func (s *Serializer) DescribeReport(rpt *report.Report, redact bool) (*report.Report, error) {
+	remote, err := s.remoteState(rpt)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.ApplyRemote(rpt, remote, redact), nil
+}
+
+// ListReport lists all parameters and secrets, in the stores the serializer uses, whose
+// name starts with any of the prefixes. The report only has the name, type and details,
+// use DescribeReport to fill in the live state.
+func (s *Serializer) ListReport(prefixes ...string) (*report.Report, error) {
+	rpt := &report.Report{Parameters: []report.Parameter{}}
+	if len(prefixes) == 0 {
+		return rpt, nil
+	}
+
+	for _, usage := range s.usageOrDefault() {
+		switch usage {
+		case UsePms:
+			pmsRepository, err := s.getAndConfigurePms()
+			if err != nil {
+				return nil, err
+			}
+
+			names, err := pmsRepository.List(prefixes...)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range names {
+				rpt.Parameters = append(rpt.Parameters, report.Parameter{Type: report.ParameterStore,
+					Name: name, Details: report.PmsParameterDetails{}})
+			}
+		case UseAsm:
+			asmRepository, err := s.getAndConfigureAsm()
+			if err != nil {
+				return nil, err
+			}
+
+			names, err := asmRepository.List(prefixes...)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range names {
+				rpt.Parameters = append(rpt.Parameters, report.Parameter{Type: report.SecretsManager,
+					Name: name, ValueType: string(types.ParameterTypeSecureString),
+					Details: report.AsmParameterDetails{}})
+			}
+		}
+	}
+
+	return rpt, nil
+}
+
+// DeleteParameters deletes the parameters and secrets by full name. Secrets are deleted
+// using the serializer recovery window. It returns the names that failed to be deleted.
+func (s *Serializer) DeleteParameters(params ...report.Parameter) (map[string]error, error) {
+	names := map[report.ParameterType][]string{}
+	failed := map[string]error{}
+
+	for _, prm := range params {
+		switch prm.Type {
+		case report.ParameterStore, report.SecretsManager:
+			names[prm.Type] = append(names[prm.Type], prm.Name)
+		default:
+			failed[prm.Name] = errors.Errorf("Unsupported parameter type %s", prm.Type)
+		}
+	}
+
+	if len(names[report.ParameterStore]) > 0 {
+		pmsRepository, err := s.getAndConfigurePms()
+		if err != nil {
+			return nil, err
+		}
+
+		for name, err := range pmsRepository.DeleteNames(names[report.ParameterStore]) {
+			failed[name] = err
+		}
+	}
+
+	if len(names[report.SecretsManager]) > 0 {
+		asmRepository, err := s.getAndConfigureAsm()
+		if err != nil {
+			return nil, err
+		}
+
+		for name, err := range asmRepository.DeleteNames(names[report.SecretsManager]) {
+			failed[name] = err
+		}
+	}
+
+	return failed, nil
+}
+
+// parametersNode creates a struct, with one string field per parameter, that holds the
+// values and parses it using the pms and asm tag parsers.
+func (s *Serializer) parametersNode(params []report.Parameter) (*parser.StructNode, error) {
+	fields := make([]reflect.StructField, len(params))
+	for i := range params {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`%s:"f%d"`, parameterUsage(&params[i]), i)),
+		}
+
+		if isStringList(&params[i]) {
+			fields[i].Type = reflect.TypeOf([]string{})
+		}
+	}
+
+	v := reflect.New(reflect.StructOf(fields))
+	for i := range params {
+		if isStringList(&params[i]) {
+			v.Elem().Field(i).Set(reflect.ValueOf(strings.Split(params[i].Value, ",")))
+		} else {
+			v.Elem().Field(i).SetString(params[i].Value)
+		}
+	}
+
+	return parser.New(s.service, s.env, "").
+		RegisterTagParser("pms", pms.NewTagParser()).
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(v)
+}
+
+// patchTag sets the name, key, description, tags and pms details of the parameter onto
+// the tag of the node.
+func patchTag(node *parser.StructNode, prm *report.Parameter) {
+	var tag *parser.StructTagImpl
+
+	if pmstag, ok := pms.ToPmsTag(node); ok {
+		tag = &pmstag.StructTagImpl
+		tag.Named["overwrite"] = "true"
+
+		if details, ok := prm.PmsDetails(); ok {
+			tag.Named["pattern"] = details.Pattern
+			tag.Named["tier"] = string(pmsTier(details.Tier))
+		}
+	} else if asmtag, ok := asm.ToAsmTag(node); ok {
+		tag = &asmtag.StructTagImpl
+
+		if details, ok := prm.AsmDetails(); ok && details.StringKey != "" {
+			tag.Named["strkey"] = details.StringKey
+		}
+	} else {
+		return
+	}
+
+	tag.FullName = prm.Name
+	tag.Named["description"] = prm.Description
+
+	if prm.KeyID != "" {
+		tag.Named["keyid"] = prm.KeyID
+	} else if snapshot.IsSecure(prm) {
+		tag.Named["keyid"] = "default"
+	}
+
+	for k, v := range prm.Tags {
+		tag.Tags[k] = v
+	}
+}
+
+// validateParameter checks that the parameter type and value type can be written and
+// that the value is not redacted, e.g. from a remote report.
+func validateParameter(prm *report.Parameter) error {
+	if prm.Value == report.Redacted {
+		return errors.Errorf("The value of %s is redacted and can not be written", prm.Name)
+	}
+
+	switch prm.Type {
+	case report.ParameterStore:
+		switch types.ParameterType(prm.ValueType) {
+		case "", types.ParameterTypeString, types.ParameterTypeStringList, types.ParameterTypeSecureString:
+			return nil
+		}
+
+		return errors.Errorf("Unsupported value type %s of %s", prm.ValueType, prm.Name)
+	case report.SecretsManager:
+		switch types.ParameterType(prm.ValueType) {
+		case "", types.ParameterTypeSecureString:
+			return nil
+		}
+
+		return errors.Errorf("Secrets are always SecureString, %s is %s", prm.Name, prm.ValueType)
+	}
+
+	return errors.Errorf("Unsupported parameter type %s", prm.Type)
+}
+
+// isStringList returns true if the parameter is a comma separated parameter store list
+func isStringList(prm *report.Parameter) bool {
+	return prm.Type == report.ParameterStore &&
+		prm.ValueType == string(types.ParameterTypeStringList)
+}
+
+// parameterUsage returns the usage that handles the parameter
+func parameterUsage(prm *report.Parameter) Usage {
+	if prm.Type == report.SecretsManager {
+		return UseAsm
+	}
+
+	return UsePms
+}
+
+// pmsTier converts the report tier to the pms tag tier
+func pmsTier(tier types.ParameterTier) pms.ParamTier {
+	switch tier {
+	case types.ParameterTierStandard:
+		return pms.Std
+	case types.ParameterTierAdvanced:
+		return pms.Adv
+	case types.ParameterTierIntelligentTiering:
+		return pms.Eval
+	}
+
+	return pms.Default
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Generated is implemented by structs that have code generated by ssmgen (see package
+// codegen). Parse uses the generated field list instead of walking the struct using
+// reflection, and the fields are populated and marshalled using typed code. The tags
+// are parsed by the registered tag parsers, hence the remote names are the same as
+// when the struct is parsed using reflection.
+type Generated interface {
+	// SsmFields lists the fields of the struct, in declaration order, along with the
+	// tags that renders the remote names.
+	SsmFields() []GeneratedField
+	// SsmPopulate sets the field, denoted by the fully qualified field name e.g.
+	// Sub.Name, from the remote value. It returns false if the field is not generated
+	// and hence must be set using reflection.
+	SsmPopulate(field string, value string) (bool, error)
+	// SsmMarshal renders the field, denoted by the fully qualified field name, as the
+	// remote value. It returns false if the field is not generated.
+	SsmMarshal(field string) (string, bool)
+}
+
+// GeneratedField is a single field in a struct with generated code
+type GeneratedField struct {
+	// Name is the go field name
+	Name string
+	// Tag is the complete field tag
+	Tag reflect.StructTag
+	// Value is the field value. It is obtained from the field address and therefore
+	// do not require any walk of the struct.
+	Value reflect.Value
+	// Fields is the fields of a nested struct declared in the same package (or inline)
+	Fields []GeneratedField
+	// Reflect is set when the type of the field is not supported by the generator,
+	// e.g. declared in another package, and the field is parsed using reflection.
+	Reflect bool
+}
+
+// parseGenerated creates the nodes of the generated fields in the same way as parse
+func (p *Parser) parseGenerated(nav string, owner *StructNode, g Generated,
+	fields []GeneratedField) ([]StructNode, error) {
+
+	t := owner.Value.Type()
+	nodes := make([]StructNode, 0, len(fields))
+
+	for _, f := range fields {
+		ft := reflect.StructField{Name: f.Name, Type: f.Value.Type(), Tag: f.Tag}
+
+		if f.Reflect {
+			node, err := p.handleKind(nav, owner, t, f.Value, ft)
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, *node)
+			continue
+		}
+
+		tag, err := p.parseTag(nav, ft)
+		if err != nil {
+			return nil, errors.Errorf("The config %s could not parse field %s", t.Name(), ft.Name)
+		}
+
+		node := &StructNode{
+			FqName:    renderFqName(nav, ft),
+			Field:     ft,
+			Owner:     owner,
+			Type:      t,
+			Value:     f.Value,
+			Tag:       tag,
+			Generated: g,
+		}
+
+		if f.Fields != nil {
+			children, err := p.parseGenerated(node.FqName, node, g, f.Fields)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(children) > 0 {
+				node.Childs = children
+			}
+		}
+
+		nodes = append(nodes, *node)
+	}
+
+	return nodes, nil
+}
@@ -64,7 +64,8 @@ func (p *Parser) RegisterTagParser(tag string, parser TagParser) *Parser {
 
 // Parse will parse the in param value. It may either be a type
 // such as var s MyStruct or a instance such as s := MyStruct{...}
-// and then do reflect.ValueOf(&s) and send that to Parse.
+// and then do reflect.ValueOf(&s) and send that to Parse. If the struct
+// implements Generated, the generated fields are used instead of reflection.
 func (p *Parser) Parse(v reflect.Value) (*StructNode, error) {
 	node := &StructNode{Type: v.Type(), Owner: nil}
 
@@ -75,7 +76,15 @@ func (p *Parser) Parse(v reflect.Value) (*StructNode, error) {
 	// Dereference the pointer
 	node.Value = reflect.Indirect(v)
 
-	nodes, err := p.parse("", node, node.Value)
+	var nodes []StructNode
+	var err error
+
+	if g, ok := v.Interface().(Generated); ok {
+		nodes, err = p.parseGenerated("", node, g, g.SsmFields())
+	} else {
+		nodes, err = p.parse("", node, node.Value)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +119,31 @@ func NodesToParameterMap(node *StructNode,
 	}
 }
 
+// NodesToParameterGroups works as NodesToParameterMap but keeps all nodes that
+// shares the same tag FullName. This is the case when several fields reads different
+// keys (jsonkey) out of one single remote JSON document. The nodes are kept in
+// the same order as they are declared in the struct.
+func NodesToParameterGroups(node *StructNode,
+	groups map[string][]*StructNode, filter *support.FieldFilters, tags []string) {
+
+	if filter.IsIncluded(node.FqName) {
+		for _, tagname := range tags {
+			if tag, ok := node.Tag[tagname]; ok {
+				if fullName := tag.GetFullName(); fullName != "" {
+					groups[fullName] = append(groups[fullName], node)
+				}
+			}
+		}
+	}
+
+	if node.HasChildren() {
+		children := node.Childs
+		for i := range node.Childs {
+			NodesToParameterGroups(&children[i], groups, filter, tags)
+		}
+	}
+}
+
 // ExtractPaths extracts all keys in the paths map and adds
 // them to an array.
 func ExtractPaths(paths map[string]*StructNode) []string {
@@ -85,6 +85,9 @@ type StructNode struct {
 	Owner *StructNode
 	// Value is used if sub-/root struct
 	Value reflect.Value
+	// Generated is the root struct, when it has generated code, that
+	// populates and marshals this field without reflection.
+	Generated Generated
 }
 
 // HasChildren returns true if this node has children
@@ -0,0 +1,17 @@
+package ssm
+
+import (
+	"github.com/mariotoffia/ssm/iam"
+)
+
+// Policy generates a least-privilege IAM policy document that grants the operations
+// on all parameters and secrets that the in param struct refers to. The names are
+// rendered using the serializer env, service and prefix.
+func (s *Serializer) Policy(v interface{}, opts iam.Options, ops ...iam.Operation) (*iam.Document, error) {
+	node, err := s.parseTagged(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return iam.Policy(node, opts, ops...)
+}
@@ -0,0 +1,267 @@
+package ssm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SkipSecure is the PromoteEntry.Skipped reason when a secure value is not promoted
+	SkipSecure = "secure"
+	// SkipMissing is the PromoteEntry.Skipped reason when the value do not exist in the from environment
+	SkipMissing = "missing"
+)
+
+// PromoteOptions controls the Promote operation
+type PromoteOptions struct {
+	// Overrides is values, keyed by the local field name (e.g. Settings.BatchSize),
+	// that is written to the to environment instead of the value in from environment.
+	Overrides map[string]string
+	// IncludeSecure must be set to promote secure parameters (keyid) and secrets.
+	// By default those are skipped.
+	IncludeSecure bool
+	// DryRun only plans the promotion, nothing is written.
+	DryRun bool
+	// Filter selects which fields to promote. If nil, all fields are promoted.
+	Filter *support.FieldFilters
+}
+
+// PromoteEntry is a single field in the promotion plan
+type PromoteEntry struct {
+	// LocalName is the field name in dotted navigation format
+	LocalName string
+	// Usage is the store, UsePms or UseAsm
+	Usage Usage
+	// From is the remote name in the from environment
+	From string
+	// To is the remote name in the to environment
+	To string
+	// Overridden is true when the value is taken from PromoteOptions.Overrides
+	Overridden bool
+	// Skipped is the reason (SkipSecure or SkipMissing) when not promoted, otherwise empty
+	Skipped string
+}
+
+// PromoteResult is the plan, and outcome, of Promote
+type PromoteResult struct {
+	// DryRun is true when nothing was written
+	DryRun bool
+	// Entries is all fields sorted by local name
+	Entries []PromoteEntry
+	// Failed is the fields that failed to be written to the to environment
+	Failed map[string]support.FullNameField
+}
+
+// String renders the plan, one line per field, prefixed with + when promoted,
+// ~ when promoted using an override and - when skipped.
+func (r *PromoteResult) String() string {
+	var sb strings.Builder
+
+	for _, e := range r.Entries {
+		switch {
+		case e.Skipped != "":
+			sb.WriteString(fmt.Sprintf("- %s (%s) %s skipped: %s\n", e.LocalName, e.Usage, e.From, e.Skipped))
+		case e.Overridden:
+			sb.WriteString(fmt.Sprintf("~ %s (%s) override -> %s\n", e.LocalName, e.Usage, e.To))
+		default:
+			sb.WriteString(fmt.Sprintf("+ %s (%s) %s -> %s\n", e.LocalName, e.Usage, e.From, e.To))
+		}
+	}
+
+	return sb.String()
+}
+
+// Promote reads all tagged fields of the in param struct from the fromEnv and writes those
+// under the toEnv. The names are resolved using the same rules as Marshal and Unmarshal,
+// hence global prefixes keep their semantics. The struct v is populated with the promoted
+// values. Secure values are skipped unless PromoteOptions.IncludeSecure is set. Values
+// that do not exist in fromEnv are skipped unless overridden.
+func (s *Serializer) Promote(v interface{}, fromEnv string, toEnv string,
+	opts PromoteOptions) (*PromoteResult, error) {
+
+	from, to := s.withEnv(fromEnv), s.withEnv(toEnv)
+
+	readFilter := copyFilters(opts.Filter)
+	plan, err := from.promotePlan(v, readFilter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	invalid, node, err := from.unmarshal(v, readFilter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := fieldNodes(node)
+	for name, value := range opts.Overrides {
+		n, ok := nodes[name]
+		if !ok {
+			return nil, errors.Errorf("Override %s do not match any field", name)
+		}
+
+		if err := common.SetStructValueFromString(n, name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	targets, err := to.remoteNames(v)
+	if err != nil {
+		return nil, err
+	}
+
+	writeFilter := copyFilters(readFilter)
+	result := &PromoteResult{DryRun: opts.DryRun, Entries: plan,
+		Failed: map[string]support.FullNameField{}}
+
+	for i := range result.Entries {
+		e := &result.Entries[i]
+		e.To = targets[e.LocalName]
+
+		if e.Skipped != "" {
+			continue
+		}
+
+		if _, missing := invalid[e.LocalName]; missing && !e.Overridden {
+			e.Skipped = SkipMissing
+			writeFilter.Exclude(e.LocalName)
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	failed, _ := to.marshal(v, writeFilter, nil)
+	for key, value := range failed {
+		result.Failed[key] = value
+	}
+
+	return result, nil
+}
+
+// withEnv returns a copy of the serializer using env
+func (s *Serializer) withEnv(env string) *Serializer {
+	clone := *s
+	clone.env = env
+	return &clone
+}
+
+// promotePlan creates the plan entries for all fields and excludes, in filter,
+// all secure fields when not included in the opts.
+func (s *Serializer) promotePlan(v interface{}, filter *support.FieldFilters,
+	opts PromoteOptions) ([]PromoteEntry, error) {
+
+	node, err := s.parseTagged(v)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []PromoteEntry{}
+
+	for _, usage := range s.usageOrDefault() {
+		groups := map[string][]*parser.StructNode{}
+		parser.NodesToParameterGroups(node, groups, filter, []string{string(usage)})
+
+		for name, group := range groups {
+			for _, n := range group {
+				e := PromoteEntry{LocalName: n.FqName, Usage: usage, From: name}
+				_, e.Overridden = opts.Overrides[n.FqName]
+
+				if isSecureField(n, usage) && !opts.IncludeSecure {
+					e.Skipped = SkipSecure
+					filter.Exclude(n.FqName)
+				}
+
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LocalName < entries[j].LocalName })
+	return entries, nil
+}
+
+// remoteNames returns the remote name of each tagged field keyed by local name
+func (s *Serializer) remoteNames(v interface{}) (map[string]string, error) {
+	node, err := s.parseTagged(v)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, usage := range s.usageOrDefault() {
+		groups := map[string][]*parser.StructNode{}
+		parser.NodesToParameterGroups(node, groups, support.NewFilters(), []string{string(usage)})
+
+		for name, group := range groups {
+			for _, n := range group {
+				names[n.FqName] = name
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// parseTagged parses v with the pms and / or asm tag parsers as of the serializer usage
+func (s *Serializer) parseTagged(v interface{}) (*parser.StructNode, error) {
+	usage := s.usageOrDefault()
+	prs := parser.New(s.service, s.env, s.prefix)
+
+	if _, found := find(usage, UsePms); found {
+		prs.RegisterTagParser("pms", pms.NewTagParser())
+	}
+	if _, found := find(usage, UseAsm); found {
+		prs.RegisterTagParser("asm", asm.NewTagParser())
+	}
+
+	return prs.Parse(reflect.ValueOf(v))
+}
+
+// isSecureField returns true for secrets and parameters backed by a KMS key
+func isSecureField(node *parser.StructNode, usage Usage) bool {
+	if usage == UseAsm {
+		return true
+	}
+
+	if tag, ok := pms.ToPmsTag(node); ok {
+		return tag.Secure()
+	}
+
+	return false
+}
+
+// fieldNodes returns all nodes in the tree keyed by local name
+func fieldNodes(node *parser.StructNode) map[string]*parser.StructNode {
+	nodes := map[string]*parser.StructNode{}
+
+	var walk func(n *parser.StructNode)
+	walk = func(n *parser.StructNode) {
+		nodes[n.FqName] = n
+		for i := range n.Childs {
+			walk(&n.Childs[i])
+		}
+	}
+
+	walk(node)
+	return nodes
+}
+
+// copyFilters returns a copy of the filters (or a new empty set if nil)
+func copyFilters(filter *support.FieldFilters) *support.FieldFilters {
+	result := support.NewFilters()
+	if filter != nil {
+		result.Filters = append(result.Filters, filter.Filters...)
+	}
+
+	return result
+}
@@ -0,0 +1,68 @@
+package ssm
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+type promoteTest struct {
+	Name   string `pms:"name"`
+	Shared string `pms:"shared, prefix=/global"`
+	Secure string `pms:"secure, keyid=default"`
+	Db     string `asm:"db"`
+}
+
+func TestPromotePlanSkipsSecureByDefault(t *testing.T) {
+	s := NewSsmSerializer("dev", "test-service")
+	filter := support.NewFilters()
+
+	plan, err := s.promotePlan(&promoteTest{}, filter, PromoteOptions{})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, []PromoteEntry{
+		{LocalName: "Db", Usage: UseAsm, From: "/dev/test-service/db", Skipped: SkipSecure},
+		{LocalName: "Name", Usage: UsePms, From: "/dev/test-service/name"},
+		{LocalName: "Secure", Usage: UsePms, From: "/dev/test-service/secure", Skipped: SkipSecure},
+		{LocalName: "Shared", Usage: UsePms, From: "/dev/global/shared"},
+	}, plan)
+
+	assert.Equal(t, false, filter.IsIncluded("Db"))
+	assert.Equal(t, true, filter.IsIncluded("Name"))
+}
+
+func TestPromotePlanIncludeSecureAndOverride(t *testing.T) {
+	s := NewSsmSerializer("dev", "test-service")
+
+	plan, err := s.promotePlan(&promoteTest{}, support.NewFilters(), PromoteOptions{
+		IncludeSecure: true,
+		Overrides:     map[string]string{"Name": "override"},
+	})
+
+	assert.Equal(t, nil, err)
+	for _, e := range plan {
+		assert.Equal(t, "", e.Skipped)
+		assert.Equal(t, e.LocalName == "Name", e.Overridden)
+	}
+}
+
+func TestPromoteToNamesKeepsGlobalPrefix(t *testing.T) {
+	names, err := NewSsmSerializer("dev", "test-service").withEnv("prod").remoteNames(&promoteTest{})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/prod/test-service/name", names["Name"])
+	assert.Equal(t, "/prod/global/shared", names["Shared"])
+}
+
+func TestPromoteResultString(t *testing.T) {
+	result := PromoteResult{Entries: []PromoteEntry{
+		{LocalName: "Db", Usage: UseAsm, From: "/dev/svc/db", To: "/prod/svc/db", Skipped: SkipSecure},
+		{LocalName: "Name", Usage: UsePms, From: "/dev/svc/name", To: "/prod/svc/name"},
+		{LocalName: "Size", Usage: UsePms, From: "/dev/svc/size", To: "/prod/svc/size", Overridden: true},
+	}}
+
+	assert.Equal(t, "- Db (asm) /dev/svc/db skipped: secure\n"+
+		"+ Name (pms) /dev/svc/name -> /prod/svc/name\n"+
+		"~ Size (pms) override -> /prod/svc/size\n", result.String())
+}
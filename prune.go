@@ -0,0 +1,200 @@
+package ssm
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// Orphan is a remote parameter or secret, under the serializer service prefix,
+// that no struct field refers to.
+type Orphan struct {
+	// Name is the remote name
+	Name string
+	// Usage is the store, UsePms or UseAsm, where the orphan lives
+	Usage Usage
+}
+
+// PruneOptions controls the Prune operation.
+type PruneOptions struct {
+	// Commit must be set to true in order to delete the orphans. By default
+	// Prune is a dry run that only reports what would have been deleted.
+	Commit bool
+	// Allow is remote names that never are pruned. A name ending with a star
+	// (e.g. /prod/test-service/legacy/*) allows all names with that prefix.
+	Allow []string
+}
+
+// PruneResult is the outcome of Prune
+type PruneResult struct {
+	// DryRun is true when nothing was deleted
+	DryRun bool
+	// Allowed is the orphans that matched the allow list and hence was kept
+	Allowed []Orphan
+	// Deleted is the orphans that was deleted (or would be when DryRun)
+	Deleted []Orphan
+	// Failed is the orphans that failed to be deleted keyed by remote name
+	Failed map[string]error
+}
+
+// Orphans lists all remote parameters and secrets under /{env}/{service} that are not
+// referenced by any of the in param structs. Those are typically left behind when a field
+// is renamed or removed. The serializer usage selects which stores to search.
+func (s *Serializer) Orphans(v ...interface{}) ([]Orphan, error) {
+	referenced, err := s.referencedNames(v...)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := parser.RenderPrefix("", s.env, s.service) + "/"
+	orphans := []Orphan{}
+
+	for _, usage := range s.usageOrDefault() {
+		var remote []string
+
+		switch usage {
+		case UsePms:
+			pmsRepository, err := s.getAndConfigurePms()
+			if err != nil {
+				return nil, err
+			}
+
+			if remote, err = pmsRepository.List(prefix); err != nil {
+				return nil, err
+			}
+		case UseAsm:
+			asmRepository, err := s.getAndConfigureAsm()
+			if err != nil {
+				return nil, err
+			}
+
+			if remote, err = asmRepository.List(prefix); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, name := range orphanNames(remote, referenced[usage]) {
+			orphans = append(orphans, Orphan{Name: name, Usage: usage})
+		}
+	}
+
+	return orphans, nil
+}
+
+// Prune finds the orphans (see Orphans) and deletes those that is not in the allow list.
+// By default it is a dry run, set PruneOptions.Commit to actually delete. Secrets are
+// deleted using the serializer recovery window.
+func (s *Serializer) Prune(opts PruneOptions, v ...interface{}) (*PruneResult, error) {
+	orphans, err := s.Orphans(v...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PruneResult{DryRun: !opts.Commit, Allowed: []Orphan{},
+		Deleted: []Orphan{}, Failed: map[string]error{}}
+
+	names := map[Usage][]string{}
+	for _, orphan := range orphans {
+		if isAllowed(opts.Allow, orphan.Name) {
+			result.Allowed = append(result.Allowed, orphan)
+			continue
+		}
+
+		names[orphan.Usage] = append(names[orphan.Usage], orphan.Name)
+	}
+
+	if opts.Commit {
+		if len(names[UsePms]) > 0 {
+			pmsRepository, err := s.getAndConfigurePms()
+			if err != nil {
+				return nil, err
+			}
+
+			for name, err := range pmsRepository.DeleteNames(names[UsePms]) {
+				result.Failed[name] = err
+			}
+		}
+
+		if len(names[UseAsm]) > 0 {
+			asmRepository, err := s.getAndConfigureAsm()
+			if err != nil {
+				return nil, err
+			}
+
+			for name, err := range asmRepository.DeleteNames(names[UseAsm]) {
+				result.Failed[name] = err
+			}
+		}
+	}
+
+	for _, orphan := range orphans {
+		if _, failed := result.Failed[orphan.Name]; !failed && !isAllowed(opts.Allow, orphan.Name) {
+			result.Deleted = append(result.Deleted, orphan)
+		}
+	}
+
+	return result, nil
+}
+
+// usageOrDefault returns the serializer usage or all tags if not set
+func (s *Serializer) usageOrDefault() []Usage {
+	if len(s.usage) > 0 {
+		return s.usage
+	}
+
+	return AllTags
+}
+
+// referencedNames parses all structs and returns the remote names, per usage,
+// that any field refers to.
+func (s *Serializer) referencedNames(v ...interface{}) (map[Usage][]string, error) {
+	referenced := map[Usage][]string{}
+	usage := s.usageOrDefault()
+
+	for _, value := range v {
+		node, err := s.parseTagged(value)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range usage {
+			m := map[string]*parser.StructNode{}
+			parser.NodesToParameterMap(node, m, support.NewFilters(), []string{string(u)})
+			referenced[u] = append(referenced[u], parser.ExtractPaths(m)...)
+		}
+	}
+
+	return referenced, nil
+}
+
+// orphanNames returns the remote names, sorted, that are not referenced.
+func orphanNames(remote []string, referenced []string) []string {
+	known := map[string]bool{}
+	for _, name := range referenced {
+		known[name] = true
+	}
+
+	orphans := []string{}
+	for _, name := range remote {
+		if !known[name] {
+			orphans = append(orphans, name)
+		}
+	}
+
+	sort.Strings(orphans)
+	return orphans
+}
+
+// isAllowed returns true if the name is in the allow list, either by exact
+// match or by a prefix ending with a star.
+func isAllowed(allow []string, name string) bool {
+	for _, a := range allow {
+		if a == name || (strings.HasSuffix(a, "*") && strings.HasPrefix(name, strings.TrimSuffix(a, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package ssm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrphanNamesAreSortedAndUnreferenced(t *testing.T) {
+	remote := []string{"/prod/svc/name", "/prod/svc/renamed", "/prod/svc/old/sub"}
+	referenced := []string{"/prod/svc/name", "/prod/svc/other"}
+
+	assert.Equal(t, []string{"/prod/svc/old/sub", "/prod/svc/renamed"}, orphanNames(remote, referenced))
+	assert.Equal(t, []string{}, orphanNames(nil, referenced))
+}
+
+func TestAllowListExactAndPrefix(t *testing.T) {
+	allow := []string{"/prod/svc/keep", "/prod/svc/legacy/*"}
+
+	assert.Equal(t, true, isAllowed(allow, "/prod/svc/keep"))
+	assert.Equal(t, false, isAllowed(allow, "/prod/svc/keepme"))
+	assert.Equal(t, true, isAllowed(allow, "/prod/svc/legacy/a/b"))
+	assert.Equal(t, false, isAllowed(allow, "/prod/svc/other"))
+}
+
+func TestReferencedNamesFromSeveralStructs(t *testing.T) {
+	type First struct {
+		Name string `pms:"name"`
+		User string `asm:"db, jsonkey=user"`
+		Pass string `asm:"db, jsonkey=password"`
+	}
+
+	type Second struct {
+		Name string `pms:"second"`
+	}
+
+	s := NewSsmSerializer("prod", "test-service")
+	referenced, err := s.referencedNames(&First{}, &Second{})
+
+	assert.Equal(t, nil, err)
+	assert.ElementsMatch(t, []string{"/prod/test-service/name", "/prod/test-service/second"}, referenced[UsePms])
+	assert.Equal(t, []string{"/prod/test-service/db"}, referenced[UseAsm])
+}
+
+func TestPruneIsDryRunByDefault(t *testing.T) {
+	if scope != "rw" {
+		return
+	}
+
+	type Test struct {
+		Name string `pms:"pruned"`
+	}
+
+	s := NewSsmSerializer(stage, "test-service")
+	s.Marshal(&Test{Name: "orphan"})
+
+	type Empty struct{}
+
+	result, err := s.Prune(PruneOptions{}, &Empty{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, result.DryRun)
+	assert.Contains(t, result.Deleted, Orphan{Name: "/" + stage + "/test-service/pruned", Usage: UsePms})
+
+	result, err = s.Prune(PruneOptions{Commit: true,
+		Allow: []string{"/" + stage + "/test-service/pruned"}}, &Empty{})
+
+	assert.Equal(t, nil, err)
+	assert.Contains(t, result.Allowed, Orphan{Name: "/" + stage + "/test-service/pruned", Usage: UsePms})
+}
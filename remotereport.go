@@ -0,0 +1,91 @@
+package ssm
+
+import (
+	"encoding/json"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// RemoteReportWithOpts generates a report of the in param type where each parameter
+// is filled with the live state: value, version, last modified date, actual tier, key,
+// description, tags and policies. Parameters that do not exist remotely has Missing set.
+// If redact is set, all secure values are replaced with report.Redacted.
+func (s *Serializer) RemoteReportWithOpts(v interface{},
+	filter *support.FieldFilters, redact bool) (*report.Report, string, error) {
+
+	local, err := s.localReport(v, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	remote, err := s.remoteState(local)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rpt := report.ApplyRemote(local, remote, redact)
+	buff, err := json.MarshalIndent(rpt, "", "  ")
+
+	return rpt, string(buff), err
+}
+
+// DiffWithOpts compares the report of the in param struct with the live state and
+// returns a side-by-side diff. This is used to detect drift between what the struct
+// declares and what is deployed. If values is set, the values of the in param
+// struct are compared as well (use e.g. a struct populated with defaults).
+func (s *Serializer) DiffWithOpts(v interface{},
+	filter *support.FieldFilters, values bool) (*report.Diff, error) {
+
+	local, err := s.localReport(v, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := s.remoteState(local)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.NewDiff(local, report.ApplyRemote(local, remote, false), values), nil
+}
+
+// localReport renders the report, including values, of v
+func (s *Serializer) localReport(v interface{}, filter *support.FieldFilters) (*report.Report, error) {
+	rpt, _, _, err := s.report(v, filter, true, nil)
+	return rpt, err
+}
+
+// remoteState fetches the live state of all parameters in the report
+func (s *Serializer) remoteState(rpt *report.Report) (map[report.ParameterType]map[string]support.RemoteState, error) {
+	names := map[report.ParameterType][]string{}
+	for _, prm := range rpt.Parameters {
+		names[prm.Type] = append(names[prm.Type], prm.Name)
+	}
+
+	remote := map[report.ParameterType]map[string]support.RemoteState{}
+
+	if len(names[report.ParameterStore]) > 0 {
+		pmsRepository, err := s.getAndConfigurePms()
+		if err != nil {
+			return nil, err
+		}
+
+		if remote[report.ParameterStore], err = pmsRepository.Describe(names[report.ParameterStore]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(names[report.SecretsManager]) > 0 {
+		asmRepository, err := s.getAndConfigureAsm()
+		if err != nil {
+			return nil, err
+		}
+
+		if remote[report.SecretsManager], err = asmRepository.Describe(names[report.SecretsManager]); err != nil {
+			return nil, err
+		}
+	}
+
+	return remote, nil
+}
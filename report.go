@@ -0,0 +1,61 @@
+package ssm
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/mariotoffia/ssm/support"
+)
+
+func (s *Serializer) report(v interface{},
+	filter *support.FieldFilters, values bool,
+	usage []Usage) (*report.Report, string, *parser.StructNode, error) {
+
+	if len(usage) == 0 {
+		usage = s.usageOrDefault()
+	}
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	tp := reflect.ValueOf(v)
+	prs := parser.New(s.service, s.env, s.prefix)
+
+	if _, found := find(usage, UsePms); found {
+		prs.RegisterTagParser("pms", pms.NewTagParser())
+	}
+	if _, found := find(usage, UseAsm); found {
+		prs.RegisterTagParser("asm", asm.NewTagParser())
+	}
+
+	for n, v := range s.parser {
+		prs.RegisterTagParser(n, v)
+	}
+
+	node, err := prs.Parse(tp)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	reporter := report.NewWithTier(s.tier)
+
+	// Register in tag order to have a deterministic contributor order
+	tags := make([]string, 0, len(s.contributors))
+	for tag := range s.contributors {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		reporter.RegisterContributor(tag, s.contributors[tag])
+	}
+
+	rpt, json, err := reporter.RenderReport(node, filter, values)
+	return rpt, json, node, err
+}
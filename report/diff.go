@@ -0,0 +1,208 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// DiffStatus is the outcome of comparing a local and remote parameter
+type DiffStatus string
+
+const (
+	// DiffEqual specifies that the remote parameter is as declared locally
+	DiffEqual DiffStatus = "equal"
+	// DiffChanged specifies that one or more fields differs between local and remote
+	DiffChanged DiffStatus = "changed"
+	// DiffMissing specifies that the parameter do not exist remotely
+	DiffMissing DiffStatus = "missing"
+)
+
+// FieldDiff is a single field that differs between local and remote
+type FieldDiff struct {
+	// Field is the name of the field, e.g. value, tier or tags.owner
+	Field string `json:"field"`
+	// Local is the value declared locally
+	Local string `json:"local"`
+	// Remote is the live value
+	Remote string `json:"remote"`
+}
+
+// ParameterDiff is the comparison of a single parameter
+type ParameterDiff struct {
+	// Name is the fully qualified name of the parameter
+	Name string `json:"fqname"`
+	// Type specifies the parameter type
+	Type ParameterType `json:"type"`
+	// Status is the outcome of the comparison
+	Status DiffStatus `json:"status"`
+	// Fields is the fields that differs when DiffChanged
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// Diff is the side-by-side comparison of a local and remote report
+type Diff struct {
+	Parameters []ParameterDiff `json:"parameters"`
+}
+
+// NewDiff compares the local report with the remote report created by ApplyRemote
+// (not redacted). Only what is declared locally is compared, i.e. extra remote tags are
+// not drift and the description and key is only compared when set locally. When values
+// is set, the values are compared as well. A local JSON document is equal if all of its
+// keys are present, with same value, in the remote document. Secure values are always
+// rendered as Redacted.
+func NewDiff(local *Report, remote *Report, values bool) *Diff {
+	states := map[string]*Parameter{}
+	for i := range remote.Parameters {
+		states[string(remote.Parameters[i].Type)+remote.Parameters[i].Name] = &remote.Parameters[i]
+	}
+
+	diff := &Diff{Parameters: []ParameterDiff{}}
+
+	for i := range local.Parameters {
+		lprm := &local.Parameters[i]
+		pd := ParameterDiff{Name: lprm.Name, Type: lprm.Type, Status: DiffEqual}
+
+		rprm, ok := states[string(lprm.Type)+lprm.Name]
+		if !ok || rprm.Missing {
+			pd.Status = DiffMissing
+			diff.Parameters = append(diff.Parameters, pd)
+			continue
+		}
+
+		pd.Fields = compareParameters(lprm, rprm, values)
+		if len(pd.Fields) > 0 {
+			pd.Status = DiffChanged
+		}
+
+		diff.Parameters = append(diff.Parameters, pd)
+	}
+
+	sort.Slice(diff.Parameters, func(i, j int) bool {
+		return diff.Parameters[i].Name < diff.Parameters[j].Name
+	})
+
+	return diff
+}
+
+// HasDrift returns true if any parameter is changed or missing
+func (d *Diff) HasDrift() bool {
+	for _, prm := range d.Parameters {
+		if prm.Status != DiffEqual {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String renders the diff side-by-side, one line per differing field, e.g.
+//
+//	STATUS   NAME             FIELD  LOCAL  REMOTE
+//	changed  /prod/svc/name   value  kalle  nisse
+func (d *Diff) String() string {
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tNAME\tFIELD\tLOCAL\tREMOTE")
+
+	for _, prm := range d.Parameters {
+		if len(prm.Fields) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t\t\t\n", prm.Status, prm.Name)
+			continue
+		}
+
+		for _, f := range prm.Fields {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", prm.Status, prm.Name, f.Field, f.Local, f.Remote)
+		}
+	}
+
+	w.Flush()
+	return sb.String()
+}
+
+// compareParameters returns the fields that differs between the local and remote parameter
+func compareParameters(local *Parameter, remote *Parameter, values bool) []FieldDiff {
+	fields := []FieldDiff{}
+
+	if values && !jsonSubset(local.Value, remote.Value) {
+		lv, rv := local.Value, remote.Value
+		if isSecure(local) || isSecure(remote) {
+			lv, rv = Redacted, Redacted
+		}
+
+		fields = append(fields, FieldDiff{Field: "value", Local: lv, Remote: rv})
+	}
+
+	if local.ValueType != remote.ValueType {
+		fields = append(fields, FieldDiff{Field: "valuetype", Local: local.ValueType, Remote: remote.ValueType})
+	}
+
+	if local.KeyID != "" && local.KeyID != remote.KeyID {
+		fields = append(fields, FieldDiff{Field: "keyid", Local: local.KeyID, Remote: remote.KeyID})
+	}
+
+	if local.Description != "" && local.Description != remote.Description {
+		fields = append(fields, FieldDiff{Field: "description", Local: local.Description, Remote: remote.Description})
+	}
+
+	ld, lok := local.PmsDetails()
+	rd, rok := remote.PmsDetails()
+
+	// Intelligent tiering resolves to standard or advanced remotely and no tier is the default
+	if lok && rok && ld.Tier != "" && ld.Tier != types.ParameterTierIntelligentTiering && ld.Tier != rd.Tier {
+		fields = append(fields, FieldDiff{Field: "tier", Local: string(ld.Tier), Remote: string(rd.Tier)})
+	}
+
+	keys := make([]string, 0, len(local.Tags))
+	for key := range local.Tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if rv, ok := remote.Tags[key]; !ok || rv != local.Tags[key] {
+			fields = append(fields, FieldDiff{Field: "tags." + key, Local: local.Tags[key], Remote: rv})
+		}
+	}
+
+	return fields
+}
+
+// jsonSubset returns true if the values are equal or both are JSON objects where
+// all keys in local exists, with same value, in remote.
+func jsonSubset(local string, remote string) bool {
+	if local == remote {
+		return true
+	}
+
+	var lv, rv interface{}
+	if json.Unmarshal([]byte(local), &lv) != nil || json.Unmarshal([]byte(remote), &rv) != nil {
+		return false
+	}
+
+	return subset(lv, rv)
+}
+
+func subset(local interface{}, remote interface{}) bool {
+	lm, lok := local.(map[string]interface{})
+	rm, rok := remote.(map[string]interface{})
+
+	if !lok || !rok {
+		return reflect.DeepEqual(local, remote)
+	}
+
+	for key, value := range lm {
+		if rvalue, ok := rm[key]; !ok || !subset(value, rvalue) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLocalReport() *Report {
+	return &Report{Parameters: []Parameter{
+		{Type: ParameterStore, Name: "/prod/svc/name", Value: "kalle", ValueType: "String",
+			Tags: map[string]string{"owner": "team"}, Details: PmsParameterDetails{Tier: "Standard"}},
+		{Type: ParameterStore, Name: "/prod/svc/pwd", Value: "local-pwd", ValueType: "SecureString",
+			Details: PmsParameterDetails{Tier: "Standard"}},
+		{Type: SecretsManager, Name: "/prod/svc/db", Value: `{"user":"admin"}`, ValueType: "SecureString",
+			Details: AsmParameterDetails{}},
+		{Type: ParameterStore, Name: "/prod/svc/missing", Value: "x", ValueType: "String",
+			Details: PmsParameterDetails{Tier: "Standard"}},
+	}}
+}
+
+func testRemoteState() map[ParameterType]map[string]support.RemoteState {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return map[ParameterType]map[string]support.RemoteState{
+		ParameterStore: {
+			"/prod/svc/name": {Name: "/prod/svc/name", Value: "nisse", Version: "3", LastModified: modified,
+				Tier: "Advanced", Type: "String", Tags: map[string]string{"owner": "team", "extra": "yes"}},
+			"/prod/svc/pwd": {Name: "/prod/svc/pwd", Value: "remote-pwd", Version: "1", LastModified: modified,
+				Tier: "Standard", Type: "SecureString", KeyID: "alias/aws/ssm", Tags: map[string]string{}},
+		},
+		SecretsManager: {
+			"/prod/svc/db": {Name: "/prod/svc/db", Value: `{"user":"admin","password":"pwd"}`,
+				Version: "abc", LastModified: modified, Tags: map[string]string{}},
+		},
+	}
+}
+
+func TestApplyRemoteFillsLiveState(t *testing.T) {
+	rpt := ApplyRemote(testLocalReport(), testRemoteState(), false)
+
+	assert.Equal(t, 4, len(rpt.Parameters))
+	assert.Equal(t, "nisse", rpt.Parameters[0].Value)
+	assert.Equal(t, "3", rpt.Parameters[0].Version)
+	assert.Equal(t, 2020, rpt.Parameters[0].LastModified.Year())
+	assert.Equal(t, "Advanced", string(rpt.Parameters[0].Details.(PmsParameterDetails).Tier))
+	assert.Equal(t, "yes", rpt.Parameters[0].Tags["extra"])
+	assert.Equal(t, "alias/aws/ssm", rpt.Parameters[1].KeyID)
+	assert.Equal(t, "remote-pwd", rpt.Parameters[1].Value)
+	assert.True(t, rpt.Parameters[3].Missing)
+	assert.Equal(t, "", rpt.Parameters[3].Value)
+}
+
+func TestApplyRemoteRedactsSecureValues(t *testing.T) {
+	rpt := ApplyRemote(testLocalReport(), testRemoteState(), true)
+
+	assert.Equal(t, "nisse", rpt.Parameters[0].Value)
+	assert.Equal(t, Redacted, rpt.Parameters[1].Value)
+	assert.Equal(t, Redacted, rpt.Parameters[2].Value)
+}
+
+func TestDiffDetectsDrift(t *testing.T) {
+	local := testLocalReport()
+	diff := NewDiff(local, ApplyRemote(local, testRemoteState(), false), true)
+
+	assert.True(t, diff.HasDrift())
+	assert.Equal(t, 4, len(diff.Parameters))
+
+	// sorted by name
+	assert.Equal(t, "/prod/svc/db", diff.Parameters[0].Name)
+	assert.Equal(t, DiffEqual, diff.Parameters[0].Status)
+
+	assert.Equal(t, DiffMissing, diff.Parameters[1].Status)
+
+	name := diff.Parameters[2]
+	assert.Equal(t, DiffChanged, name.Status)
+	assert.Equal(t, []FieldDiff{
+		{Field: "value", Local: "kalle", Remote: "nisse"},
+		{Field: "tier", Local: "Standard", Remote: "Advanced"},
+	}, name.Fields)
+
+	pwd := diff.Parameters[3]
+	assert.Equal(t, DiffChanged, pwd.Status)
+	assert.Equal(t, []FieldDiff{{Field: "value", Local: Redacted, Remote: Redacted}}, pwd.Fields)
+
+	str := diff.String()
+	assert.Contains(t, str, "STATUS")
+	assert.Contains(t, str, "/prod/svc/missing")
+	assert.NotContains(t, str, "remote-pwd")
+}
+
+func TestDiffWithoutValuesIgnoresValues(t *testing.T) {
+	local := testLocalReport()
+	local.Parameters = local.Parameters[1:3]
+
+	diff := NewDiff(local, ApplyRemote(local, testRemoteState(), false), false)
+	assert.False(t, diff.HasDrift())
+}
+
+func TestDiffComparesTierOfJSONDetails(t *testing.T) {
+	local := &Report{Parameters: []Parameter{
+		{Type: ParameterStore, Name: "/prod/svc/name", Value: "nisse", ValueType: "String",
+			Details: map[string]interface{}{"tier": "Standard"}},
+		{Type: ParameterStore, Name: "/prod/svc/pwd", Value: "remote-pwd", ValueType: "SecureString",
+			Details: map[string]interface{}{"tier": ""}},
+	}}
+
+	diff := NewDiff(local, ApplyRemote(local, testRemoteState(), false), true)
+
+	assert.Equal(t, []FieldDiff{{Field: "tier", Local: "Standard", Remote: "Advanced"}}, diff.Parameters[0].Fields)
+	assert.Equal(t, DiffEqual, diff.Parameters[1].Status)
+}
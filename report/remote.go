@@ -0,0 +1,67 @@
+package report
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// Redacted is the value of secure parameters in a redacted remote report and in a Diff
+const Redacted = "*** redacted ***"
+
+// ApplyRemote creates a remote report out of the local report. Each parameter is filled
+// with the live state in remote (keyed by parameter type and remote name): value, version, last modified,
+// tier, key, description, tags and policies. Parameters that do not exist in remote has
+// Missing set. Parameters whose type is not in remote are left untouched. If redact is set, secure values are replaced with Redacted.
+func ApplyRemote(local *Report, remote map[ParameterType]map[string]support.RemoteState,
+	redact bool) *Report {
+
+	params := make([]Parameter, 0, len(local.Parameters))
+
+	for _, prm := range local.Parameters {
+		states, known := remote[prm.Type]
+		if !known {
+			// Not fetched, e.g. a contributor parameter type
+			params = append(params, prm)
+			continue
+		}
+
+		state, ok := states[prm.Name]
+		if !ok {
+			prm.Missing, prm.Value = true, ""
+			params = append(params, prm)
+			continue
+		}
+
+		lastModified := state.LastModified
+
+		prm.Value = state.Value
+		prm.Version = state.Version
+		prm.LastModified = &lastModified
+		prm.KeyID = state.KeyID
+		prm.Description = state.Description
+		prm.Tags = state.Tags
+		prm.Policies = state.Policies
+
+		if state.Type != "" {
+			prm.ValueType = state.Type
+		}
+
+		if details, ok := prm.PmsDetails(); ok {
+			details.Tier = types.ParameterTier(state.Tier)
+			prm.Details = details
+		}
+
+		if redact && isSecure(&prm) {
+			prm.Value = Redacted
+		}
+
+		params = append(params, prm)
+	}
+
+	return &Report{Parameters: params}
+}
+
+// isSecure returns true if the value of the parameter is encrypted
+func isSecure(prm *Parameter) bool {
+	return prm.ValueType == string(types.ParameterTypeSecureString)
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
 	"github.com/mariotoffia/ssm/internal/asm"
@@ -47,6 +48,14 @@ type Parameter struct {
 	Value string `json:"value"`
 	// The type of the value. For example PMS has String|StringList|SecureString.
 	ValueType string `json:"valuetype"`
+	// Version is the remote version, only set in a remote report
+	Version string `json:"version,omitempty"`
+	// LastModified is when the remote parameter was last changed, only set in a remote report
+	LastModified *time.Time `json:"lastmodified,omitempty"`
+	// Policies is the remote parameter policies or secret resource policy, only set in a remote report
+	Policies []string `json:"policies,omitempty"`
+	// Missing is true, in a remote report, when the parameter do not exist remotely
+	Missing bool `json:"missing,omitempty"`
 }
 
 // PmsParameterDetails describes the details for a parameter store parameter
@@ -55,6 +64,9 @@ type PmsParameterDetails struct {
 	Pattern string `json:"pattern"`
 	// Tier specifies the tier for the parameter
 	Tier types.ParameterTier `json:"tier"`
+	// JSONKeys is the set of keys that fields reads from the JSON document
+	// stored in the parameter. If empty the whole value is used by one field.
+	JSONKeys []string `json:"jsonkeys,omitempty"`
 }
 
 // AsmParameterDetails specifies Secrets Manager secret specifics
@@ -62,11 +74,27 @@ type AsmParameterDetails struct {
 	// StringKey is the name of the json key where the Secrets Manager shall genereate it's secret into.
 	// This is for template driven secrets where a JSON payload is set into the SecretString
 	StringKey string `json:"strkey"`
+	// JSONKeys is the set of keys that fields reads from the JSON document
+	// stored in the secret. If empty the whole value is used by one field.
+	JSONKeys []string `json:"jsonkeys,omitempty"`
+}
+
+// Contributor renders the report parameter of nodes that has a custom tag. It is
+// registered on the Reporter, per tag, using RegisterContributor. The parameter
+// may use its own ParameterType and details.
+type Contributor interface {
+	// ReportParameter creates the parameter for the node or nil if the node shall not
+	// be reported. When value is set, the Value of the parameter shall be populated.
+	ReportParameter(node *parser.StructNode, value bool) *Parameter
 }
 
 // Reporter is the type to produce report of the configuration
 type Reporter struct {
 	tier types.ParameterTier
+	// tags is the contributor tags in registration order
+	tags []string
+	// contributors is the custom tag contributors keyed by tag name
+	contributors map[string]Contributor
 }
 
 // New creates a new Reporter with ssm.ParameterTierStandard
@@ -76,7 +104,18 @@ func New() *Reporter {
 
 // NewWithTier creates a new Reporter with specified tier
 func NewWithTier(tier types.ParameterTier) *Reporter {
-	return &Reporter{tier: tier}
+	return &Reporter{tier: tier, contributors: map[string]Contributor{}}
+}
+
+// RegisterContributor registers a contributor that renders the parameters of all nodes
+// that has the tag. The pms and asm tags are always rendered by the Reporter itself.
+func (r *Reporter) RegisterContributor(tag string, contributor Contributor) *Reporter {
+	if _, ok := r.contributors[tag]; !ok {
+		r.tags = append(r.tags, tag)
+	}
+
+	r.contributors[tag] = contributor
+	return r
 }
 
 // RenderReport renders a JSON report based on the node tree and filters
@@ -96,16 +135,25 @@ func (r *Reporter) renderReport(node *parser.StructNode,
 	filter *support.FieldFilters, params []Parameter, value bool) []Parameter {
 
 	var prm *Parameter
+	contributed := false
+
 	if filter.IsIncluded(node.FqName) {
+		tagname := ""
 		if pmstag, ok := pms.ToPmsTag(node); ok {
-			prm = r.handlePmsTag(pmstag)
+			prm, tagname = r.handlePmsTag(pmstag), "pms"
 		} else if asmtag, ok := asm.ToAsmTag(node); ok {
-			prm = r.handleAsmTag(asmtag)
+			prm, tagname = r.handleAsmTag(asmtag), "asm"
+		} else if cprm := r.contribute(node, value); cprm != nil {
+			params, contributed = append(params, *cprm), true
 		} else {
-			log.Debug().Msgf("node %s has not pms or asm tag", node.FqName)
+			log.Debug().Msgf("node %s has not pms, asm or contributor tag", node.FqName)
 		}
 
 		if prm != nil {
+			if key := common.JSONKey(node, tagname); key != "" {
+				return r.mergeJSONKey(node, tagname, key, prm, params, value)
+			}
+
 			if value {
 				prm.Value = common.GetStringValueFromField(node)
 			}
@@ -117,7 +165,7 @@ func (r *Reporter) renderReport(node *parser.StructNode,
 	if node.HasChildren() {
 		if prm != nil {
 			prm.Value = common.GetStringValueFromField(node)
-		} else {
+		} else if !contributed {
 			children := node.Childs
 			for i := range node.Childs {
 				params = r.renderReport(&children[i], filter, params, value)
@@ -128,6 +176,62 @@ func (r *Reporter) renderReport(node *parser.StructNode,
 	return params
 }
 
+// mergeJSONKey merges fields that reads a jsonkey out of the same remote parameter
+// into a single Parameter. The value is the merged JSON document of all such fields.
+func (r *Reporter) mergeJSONKey(node *parser.StructNode, tagname string, key string,
+	prm *Parameter, params []Parameter, value bool) []Parameter {
+
+	idx := -1
+	for i := range params {
+		if params[i].Name == prm.Name && params[i].Type == prm.Type {
+			idx = i
+			break
+		}
+	}
+
+	if idx == -1 {
+		params = append(params, *prm)
+		idx = len(params) - 1
+	}
+
+	existing := &params[idx]
+	switch details := existing.Details.(type) {
+	case PmsParameterDetails:
+		details.JSONKeys = append(details.JSONKeys, key)
+		existing.Details = details
+	case AsmParameterDetails:
+		details.JSONKeys = append(details.JSONKeys, key)
+		existing.Details = details
+	}
+
+	if value {
+		doc, err := common.MergeJSONKeys(existing.Value, []*parser.StructNode{node}, tagname)
+		if err != nil {
+			log.Warn().Msgf("failed to merge jsonkey %s for field %s error: %v", key, node.FqName, err)
+		} else {
+			existing.Value = doc
+		}
+	}
+
+	return params
+}
+
+// contribute renders the node using the first registered contributor whose tag is
+// present on the node. If none, nil is returned.
+func (r *Reporter) contribute(node *parser.StructNode, value bool) *Parameter {
+	for _, tag := range r.tags {
+		if _, ok := node.Tag[tag]; !ok {
+			continue
+		}
+
+		if prm := r.contributors[tag].ReportParameter(node, value); prm != nil {
+			return prm
+		}
+	}
+
+	return nil
+}
+
 func (r *Reporter) handleAsmTag(asmtag *asm.AsmTagStruct) *Parameter {
 	prm := &Parameter{
 		Name:        asmtag.GetFullName(),
@@ -178,3 +282,57 @@ func (r *Reporter) handlePmsTag(pmstag *pms.PmsTagStruct) *Parameter {
 
 	return prm
 }
+
+// PmsDetails returns the details of a parameter store parameter. The details may
+// either be PmsParameterDetails or, when the report is read from JSON, a JSON object.
+func (p *Parameter) PmsDetails() (PmsParameterDetails, bool) {
+	var details PmsParameterDetails
+	if d, ok := p.Details.(PmsParameterDetails); ok {
+		return d, true
+	}
+
+	return details, p.Type == ParameterStore && decodeDetails(p.Details, &details)
+}
+
+// AsmDetails returns the details of a secrets manager secret. The details may
+// either be AsmParameterDetails or, when the report is read from JSON, a JSON object.
+func (p *Parameter) AsmDetails() (AsmParameterDetails, bool) {
+	var details AsmParameterDetails
+	if d, ok := p.Details.(AsmParameterDetails); ok {
+		return d, true
+	}
+
+	return details, p.Type == SecretsManager && decodeDetails(p.Details, &details)
+}
+
+// decodeDetails decodes generic JSON decoded details into v
+func decodeDetails(details interface{}, v interface{}) bool {
+	if details == nil {
+		return false
+	}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(data, v) == nil
+}
+
+// SecretStringTemplate returns the value without the strkey, i.e. the template to generate
+// the strkey value into. If the value is not a JSON object an empty object is returned.
+func (p *Parameter) SecretStringTemplate(strkey string) string {
+	doc := map[string]interface{}{}
+	if json.Unmarshal([]byte(p.Value), &doc) != nil {
+		return "{}"
+	}
+
+	delete(doc, strkey)
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(data)
+}
@@ -148,3 +148,29 @@ func TestReportPostgresSQLTemplateStruct(t *testing.T) {
 	assert.Equal(t, 2, len(report.Parameters))
 	fmt.Println(buff)
 }
+
+func TestReportJSONKeyFieldsMergedIntoSingleParameter(t *testing.T) {
+	test := testsupport.JSONKeyAsmStruct{User: "admin", Host: "db.local", Port: 5432}
+	tp := reflect.ValueOf(&test)
+
+	node, err := parser.New("test-service", "prod", "").
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(tp)
+
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	reporter := NewWithTier(types.ParameterTierStandard)
+	report, buff, err := reporter.RenderReport(node, &support.FieldFilters{}, true)
+	if err != nil {
+		assert.Equal(t, nil, err)
+	}
+
+	assert.Equal(t, 1, len(report.Parameters))
+	assert.Equal(t, "/prod/test-service/rds", report.Parameters[0].Name)
+	assert.Equal(t, `{"host":"db.local","password":"","port":5432,"username":"admin"}`, report.Parameters[0].Value)
+	assert.Equal(t, []string{"username", "password", "/host", "port"},
+		report.Parameters[0].Details.(AsmParameterDetails).JSONKeys)
+	fmt.Println(buff)
+}
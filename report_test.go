@@ -0,0 +1,91 @@
+package ssm
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+type reportTest struct {
+	Name   string `pms:"name"`
+	Shared string `pms:"shared, prefix=/global"`
+	Db     string `asm:"db"`
+	Flag   string `cfg:"flag"`
+}
+
+type cfgContributor struct{}
+
+func (c *cfgContributor) ReportParameter(node *parser.StructNode, value bool) *report.Parameter {
+	prm := &report.Parameter{Type: "app-config", Name: node.Tag["cfg"].GetFullName(),
+		Details: map[string]string{"app": "myapp"}}
+
+	if value {
+		prm.Value = common.GetStringValueFromField(node)
+	}
+
+	return prm
+}
+
+func TestReportUsesSerializerEnvAndService(t *testing.T) {
+	s := NewSsmSerializer("dev", "my-service")
+
+	rpt, _, err := s.ReportWithOpts(&reportTest{Name: "kalle"}, NoFilter, true)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 3, len(rpt.Parameters))
+	assert.Equal(t, "/dev/my-service/name", rpt.Parameters[0].Name)
+	assert.Equal(t, "kalle", rpt.Parameters[0].Value)
+	assert.Equal(t, "/dev/global/shared", rpt.Parameters[1].Name)
+	assert.Equal(t, "/dev/my-service/db", rpt.Parameters[2].Name)
+}
+
+func TestReportHonorsPrefixAndValues(t *testing.T) {
+	s := NewSsmSerializer("dev", "my-service").UsePrefix("nested")
+
+	rpt, _, err := s.ReportWithOpts(&reportTest{Name: "kalle"}, NoFilter, false)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, "/dev/my-service/nested/name", rpt.Parameters[0].Name)
+	assert.Equal(t, "", rpt.Parameters[0].Value)
+}
+
+func TestAdvReportHonorsUsage(t *testing.T) {
+	s := NewSsmSerializer("dev", "my-service")
+
+	rpt, _, node, err := s.AdvReportWithOpts(&reportTest{}, NoFilter, true, OnlyAsm)
+	assert.Equal(t, nil, err)
+	assert.NotNil(t, node)
+
+	assert.Equal(t, 1, len(rpt.Parameters))
+	assert.Equal(t, report.SecretsManager, rpt.Parameters[0].Type)
+}
+
+func TestReportWithCustomContributor(t *testing.T) {
+	s := NewSsmSerializer("dev", "my-service").
+		UseTagParser("cfg", parser.NewTagParser([]string{"name", "prefix"})).
+		UseReportContributor("cfg", &cfgContributor{})
+
+	rpt, json, err := s.ReportWithOpts(&reportTest{Flag: "on"}, NoFilter, true)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 4, len(rpt.Parameters))
+	assert.Equal(t, report.ParameterType("app-config"), rpt.Parameters[3].Type)
+	assert.Equal(t, "/dev/my-service/flag", rpt.Parameters[3].Name)
+	assert.Equal(t, "on", rpt.Parameters[3].Value)
+	assert.Contains(t, json, `"app": "myapp"`)
+}
+
+func TestDocsNeverContainsSecureValues(t *testing.T) {
+	s := NewSsmSerializer("dev", "my-service")
+
+	doc, err := s.DocsWithOpts(&reportTest{Name: "kalle", Db: "s3cr3t"}, NoFilter, "My Service")
+	assert.Equal(t, nil, err)
+
+	md := doc.Markdown()
+	assert.Contains(t, md, "| `Name` | `/dev/my-service/name` | pms | `string` | String | Standard | no | `kalle` | no |  |")
+	assert.Contains(t, md, "`/dev/my-service/db`")
+	assert.NotContains(t, md, "s3cr3t")
+}
@@ -0,0 +1,261 @@
+package rotation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultPasswordLength is the length of generated passwords
+	DefaultPasswordLength = 32
+	// DefaultExcludeCharacters is the characters not allowed in RDS passwords
+	DefaultExcludeCharacters = "/@\"'\\"
+	// cloneSuffix is appended to the username on every other multi user rotation
+	cloneSuffix = "_clone"
+)
+
+type database struct {
+	setter PasswordSetter
+	tester LoginTester
+}
+
+// Handler implements the four Secrets Manager rotation steps for the rotation
+// templates in the support package. The database specific parts are registered
+// per engine using RegisterDatabase.
+type Handler struct {
+	store     SecretStore
+	databases map[support.ASMEngine]database
+	length    int
+	exclude   string
+}
+
+// NewHandler creates a new rotation handler that uses the store to read and
+// write secret versions.
+func NewHandler(store SecretStore) *Handler {
+	return &Handler{
+		store:     store,
+		databases: map[support.ASMEngine]database{},
+		length:    DefaultPasswordLength,
+		exclude:   DefaultExcludeCharacters,
+	}
+}
+
+// RegisterDatabase registers the database specific set password and test login
+// implementation for an engine.
+func (h *Handler) RegisterDatabase(engine support.ASMEngine,
+	setter PasswordSetter, tester LoginTester) *Handler {
+
+	h.databases[engine] = database{setter: setter, tester: tester}
+	return h
+}
+
+// SetPasswordPolicy sets the length and the characters to exclude when generating
+// passwords. By default DefaultPasswordLength and DefaultExcludeCharacters is used.
+func (h *Handler) SetPasswordPolicy(length int, exclude string) *Handler {
+	h.length = length
+	h.exclude = exclude
+	return h
+}
+
+// Rotate performs the rotation step in the event. This may be invoked directly
+// from the rotation lambda.
+func (h *Handler) Rotate(ctx context.Context, event Event) error {
+	desc, err := h.store.DescribeSecret(ctx, event.SecretID)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to describe secret %s", event.SecretID)
+	}
+
+	if !desc.RotationEnabled {
+		return errors.Errorf("Secret %s is not enabled for rotation", event.SecretID)
+	}
+
+	stages, ok := desc.Versions[event.ClientRequestToken]
+	if !ok {
+		return errors.Errorf("Secret version %s has no stage for rotation of secret %s",
+			event.ClientRequestToken, event.SecretID)
+	}
+
+	if hasStage(stages, StageCurrent) {
+		log.Debug().Str("method", "Rotate").
+			Msgf("secret version %s already set as AWSCURRENT for secret %s", event.ClientRequestToken, event.SecretID)
+		return nil
+	}
+
+	if !hasStage(stages, StagePending) {
+		return errors.Errorf("Secret version %s not set as AWSPENDING for rotation of secret %s",
+			event.ClientRequestToken, event.SecretID)
+	}
+
+	switch event.Step {
+	case CreateSecret:
+		return h.createSecret(ctx, event)
+	case SetSecret:
+		return h.setSecret(ctx, event)
+	case TestSecret:
+		return h.testSecret(ctx, event)
+	case FinishSecret:
+		return h.finishSecret(ctx, event, desc)
+	}
+
+	return errors.Errorf("Invalid rotation step %s", event.Step)
+}
+
+// createSecret generates a new password and stores it as AWSPENDING unless
+// already present.
+func (h *Handler) createSecret(ctx context.Context, event Event) error {
+	current, err := h.secret(ctx, event.SecretID, "", StageCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.store.GetSecretValue(ctx, event.SecretID, event.ClientRequestToken, StagePending); err == nil {
+		log.Debug().Str("method", "createSecret").Msgf("pending secret already exist for %s", event.SecretID)
+		return nil
+	} else if errors.Cause(err) != ErrVersionNotFound {
+		return err
+	}
+
+	password, err := h.store.GetRandomPassword(ctx, h.length, h.exclude)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to generate password for %s", event.SecretID)
+	}
+
+	if current.IsMultiUser() {
+		current.Username = alternateUsername(current.Username)
+	}
+
+	current.Password = password
+
+	if err := h.store.PutSecretValue(ctx, event.SecretID, event.ClientRequestToken,
+		current.String(), []string{StagePending}); err != nil {
+		return errors.Wrapf(err, "Failed to put pending secret for %s", event.SecretID)
+	}
+
+	log.Debug().Str("method", "createSecret").Msgf("created pending secret for %s", event.SecretID)
+	return nil
+}
+
+// setSecret sets the AWSPENDING password in the database.
+func (h *Handler) setSecret(ctx context.Context, event Event) error {
+	pending, err := h.secret(ctx, event.SecretID, event.ClientRequestToken, StagePending)
+	if err != nil {
+		return err
+	}
+
+	db, err := h.database(pending)
+	if err != nil {
+		return err
+	}
+
+	// Already set, e.g. if the step is retried
+	if err := db.tester.TestLogin(ctx, pending); err == nil {
+		log.Debug().Str("method", "setSecret").Msgf("pending secret already set in database for %s", event.SecretID)
+		return nil
+	}
+
+	current, err := h.secret(ctx, event.SecretID, "", StageCurrent)
+	if err != nil {
+		return err
+	}
+
+	request := SetPasswordRequest{Pending: pending, Current: current}
+
+	if pending.IsMultiUser() {
+		if request.Master, err = h.secret(ctx, pending.MasterArn, "", StageCurrent); err != nil {
+			return err
+		}
+	}
+
+	if err := db.setter.SetPassword(ctx, request); err != nil {
+		return errors.Wrapf(err, "Failed to set password in database for %s", event.SecretID)
+	}
+
+	return nil
+}
+
+// testSecret tests to login to the database using the AWSPENDING secret.
+func (h *Handler) testSecret(ctx context.Context, event Event) error {
+	pending, err := h.secret(ctx, event.SecretID, event.ClientRequestToken, StagePending)
+	if err != nil {
+		return err
+	}
+
+	db, err := h.database(pending)
+	if err != nil {
+		return err
+	}
+
+	if err := db.tester.TestLogin(ctx, pending); err != nil {
+		return errors.Wrapf(err, "Failed to login using pending secret for %s", event.SecretID)
+	}
+
+	return nil
+}
+
+// finishSecret moves the AWSCURRENT stage to the AWSPENDING version. Secrets
+// Manager will then put the AWSPREVIOUS onto the last current version.
+func (h *Handler) finishSecret(ctx context.Context, event Event, desc *SecretDescription) error {
+	current := ""
+	for version, stages := range desc.Versions {
+		if hasStage(stages, StageCurrent) {
+			current = version
+			break
+		}
+	}
+
+	if err := h.store.UpdateSecretVersionStage(ctx, event.SecretID, StageCurrent,
+		event.ClientRequestToken, current); err != nil {
+		return errors.Wrapf(err, "Failed to move AWSCURRENT for %s", event.SecretID)
+	}
+
+	if err := h.store.UpdateSecretVersionStage(ctx, event.SecretID, StagePending,
+		"", event.ClientRequestToken); err != nil {
+		return errors.Wrapf(err, "Failed to remove AWSPENDING for %s", event.SecretID)
+	}
+
+	log.Debug().Str("method", "finishSecret").
+		Msgf("moved AWSCURRENT to version %s for %s", event.ClientRequestToken, event.SecretID)
+
+	return nil
+}
+
+func (h *Handler) secret(ctx context.Context, secretID string, versionID string, stage string) (*Secret, error) {
+	version, err := h.store.GetSecretValue(ctx, secretID, versionID, stage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get %s secret %s", stage, secretID)
+	}
+
+	return ParseSecret(version.Value)
+}
+
+func (h *Handler) database(secret *Secret) (database, error) {
+	if db, ok := h.databases[secret.Engine]; ok {
+		return db, nil
+	}
+
+	return database{}, errors.Errorf("No database registered for engine '%s'", secret.Engine)
+}
+
+// alternateUsername toggles the clone suffix so multi user rotation alternates
+// between two users.
+func alternateUsername(username string) string {
+	if strings.HasSuffix(username, cloneSuffix) {
+		return strings.TrimSuffix(username, cloneSuffix)
+	}
+
+	return username + cloneSuffix
+}
+
+func hasStage(stages []string, stage string) bool {
+	for _, s := range stages {
+		if s == stage {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,229 @@
+package rotation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/pkg/errors"
+)
+
+type memoryVersion struct {
+	value    string
+	hasValue bool
+	stages   []string
+}
+
+type memorySecret struct {
+	rotationEnabled bool
+	versions        map[string]*memoryVersion
+}
+
+// MemoryStore is a in memory SecretStore that behaves as Secrets Manager in
+// regards to versions and staging labels. This is mainly intended for testing
+// rotation without any AWS account.
+type MemoryStore struct {
+	mu      sync.Mutex
+	secrets map[string]*memorySecret
+}
+
+// NewMemoryStore creates a new empty in memory secret store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{secrets: map[string]*memorySecret{}}
+}
+
+// CreateSecret creates a secret with rotation enabled and a single AWSCURRENT
+// version holding value. It returns the version id.
+func (m *MemoryStore) CreateSecret(secretID string, value string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	version := uuid.New().String()
+	m.secrets[secretID] = &memorySecret{
+		rotationEnabled: true,
+		versions: map[string]*memoryVersion{
+			version: {value: value, hasValue: true, stages: []string{StageCurrent}},
+		},
+	}
+
+	return version
+}
+
+// StartRotation does what Secrets Manager does before invoking the rotation; it
+// attaches the AWSPENDING stage onto a new (empty) version. It returns the event
+// for the createSecret step.
+func (m *MemoryStore) StartRotation(secretID string) (Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[secretID]
+	if !ok {
+		return Event{}, ErrVersionNotFound
+	}
+
+	token := uuid.New().String()
+	removeStage(secret, StagePending)
+	secret.versions[token] = &memoryVersion{stages: []string{StagePending}}
+
+	return Event{SecretID: secretID, ClientRequestToken: token, Step: CreateSecret}, nil
+}
+
+// DescribeSecret implements SecretStore
+func (m *MemoryStore) DescribeSecret(ctx context.Context, secretID string) (*SecretDescription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[secretID]
+	if !ok {
+		return nil, errors.Errorf("Secret %s not found", secretID)
+	}
+
+	desc := &SecretDescription{Name: secretID, RotationEnabled: secret.rotationEnabled,
+		Versions: map[string][]string{}}
+
+	for id, version := range secret.versions {
+		if len(version.stages) > 0 {
+			desc.Versions[id] = append([]string{}, version.stages...)
+		}
+	}
+
+	return desc, nil
+}
+
+// GetSecretValue implements SecretStore
+func (m *MemoryStore) GetSecretValue(ctx context.Context,
+	secretID string, versionID string, stage string) (*SecretVersion, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[secretID]
+	if !ok {
+		return nil, ErrVersionNotFound
+	}
+
+	for id, version := range secret.versions {
+		if versionID != "" && id != versionID {
+			continue
+		}
+
+		if stage != "" && !hasStage(version.stages, stage) {
+			continue
+		}
+
+		if !version.hasValue {
+			return nil, ErrVersionNotFound
+		}
+
+		return &SecretVersion{Name: secretID, VersionID: id,
+			Stages: append([]string{}, version.stages...), Value: version.value}, nil
+	}
+
+	return nil, ErrVersionNotFound
+}
+
+// PutSecretValue implements SecretStore
+func (m *MemoryStore) PutSecretValue(ctx context.Context,
+	secretID string, versionID string, value string, stages []string) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[secretID]
+	if !ok {
+		return errors.Errorf("Secret %s not found", secretID)
+	}
+
+	if version, ok := secret.versions[versionID]; ok && version.hasValue {
+		if version.value != value {
+			return errors.Errorf("Version %s already exists with a different value", versionID)
+		}
+
+		return nil
+	}
+
+	for _, stage := range stages {
+		removeStage(secret, stage)
+	}
+
+	secret.versions[versionID] = &memoryVersion{value: value, hasValue: true,
+		stages: append([]string{}, stages...)}
+
+	return nil
+}
+
+// UpdateSecretVersionStage implements SecretStore. As Secrets Manager, moving the
+// AWSCURRENT stage will put AWSPREVIOUS on the version that was current.
+func (m *MemoryStore) UpdateSecretVersionStage(ctx context.Context,
+	secretID string, stage string, moveTo string, removeFrom string) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[secretID]
+	if !ok {
+		return errors.Errorf("Secret %s not found", secretID)
+	}
+
+	if removeFrom != "" {
+		version, ok := secret.versions[removeFrom]
+		if !ok || !hasStage(version.stages, stage) {
+			return errors.Errorf("Stage %s is not attached to version %s", stage, removeFrom)
+		}
+
+		version.stages = without(version.stages, stage)
+	}
+
+	if moveTo == "" {
+		return nil
+	}
+
+	target, ok := secret.versions[moveTo]
+	if !ok {
+		return errors.Errorf("Version %s not found", moveTo)
+	}
+
+	for _, version := range secret.versions {
+		if hasStage(version.stages, stage) {
+			return errors.Errorf("Stage %s is attached to another version", stage)
+		}
+	}
+
+	if stage == StageCurrent && removeFrom != "" {
+		removeStage(secret, StagePrevious)
+		previous := secret.versions[removeFrom]
+		previous.stages = append(previous.stages, StagePrevious)
+	}
+
+	target.stages = append(target.stages, stage)
+	return nil
+}
+
+// GetRandomPassword implements SecretStore using crypto/rand
+func (m *MemoryStore) GetRandomPassword(ctx context.Context, length int, exclude string) (string, error) {
+	return RandomPassword(length, exclude)
+}
+
+// RandomPassword generates a cryptographically random password of length
+// characters where none of the characters in exclude is used.
+func RandomPassword(length int, exclude string) (string, error) {
+	return common.RandomString(length, "", exclude)
+}
+
+func removeStage(secret *memorySecret, stage string) {
+	for _, version := range secret.versions {
+		version.stages = without(version.stages, stage)
+	}
+}
+
+func without(stages []string, stage string) []string {
+	result := []string{}
+	for _, s := range stages {
+		if s != stage {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,166 @@
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDatabase keeps username / password in memory
+type fakeDatabase struct {
+	users    map[string]string
+	setCalls int
+}
+
+func (db *fakeDatabase) SetPassword(ctx context.Context, request SetPasswordRequest) error {
+	login := request.Current
+	if request.Master != nil {
+		login = request.Master
+	}
+
+	if db.users[login.Username] != login.Password {
+		return fmt.Errorf("login failed for %s", login.Username)
+	}
+
+	db.setCalls++
+	db.users[request.Pending.Username] = request.Pending.Password
+	return nil
+}
+
+func (db *fakeDatabase) TestLogin(ctx context.Context, secret *Secret) error {
+	if pwd, ok := db.users[secret.Username]; !ok || pwd != secret.Password {
+		return fmt.Errorf("login failed for %s", secret.Username)
+	}
+
+	return nil
+}
+
+func rotate(t *testing.T, handler *Handler, store *MemoryStore, secretID string) {
+	event, err := store.StartRotation(secretID)
+	assert.Equal(t, nil, err)
+
+	for _, step := range []Step{CreateSecret, SetSecret, TestSecret, FinishSecret} {
+		event.Step = step
+		assert.Equal(t, nil, handler.Rotate(context.Background(), event), string(step))
+	}
+}
+
+func TestRotateSingleUserPostgres(t *testing.T) {
+	store := NewMemoryStore()
+	db := &fakeDatabase{users: map[string]string{"admin": "initial"}}
+
+	store.CreateSecret("/prod/test-service/dbctx",
+		`{"engine":"postgres","host":"pg.local","username":"admin","password":"initial","port":5432}`)
+
+	handler := NewHandler(store).RegisterDatabase(support.PostgresDBEngine, db, db)
+	rotate(t, handler, store, "/prod/test-service/dbctx")
+
+	current, err := store.GetSecretValue(context.Background(), "/prod/test-service/dbctx", "", StageCurrent)
+	assert.Equal(t, nil, err)
+
+	secret, err := ParseSecret(current.Value)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "admin", secret.Username)
+	assert.Equal(t, DefaultPasswordLength, len(secret.Password))
+	assert.Equal(t, db.users["admin"], secret.Password)
+	assert.Equal(t, "5432", secret.Port)
+	assert.Contains(t, current.Value, `"port":5432`)
+
+	previous, err := store.GetSecretValue(context.Background(), "/prod/test-service/dbctx", "", StagePrevious)
+	assert.Equal(t, nil, err)
+	assert.Contains(t, previous.Value, `"password":"initial"`)
+
+	_, err = store.GetSecretValue(context.Background(), "/prod/test-service/dbctx", "", StagePending)
+	assert.Equal(t, ErrVersionNotFound, err)
+}
+
+func TestRotateMultiUserAlternatesUsers(t *testing.T) {
+	store := NewMemoryStore()
+	db := &fakeDatabase{users: map[string]string{"master": "m4st3r", "app": "initial"}}
+
+	store.CreateSecret("/prod/global/master",
+		`{"engine":"mysql","host":"my.local","username":"master","password":"m4st3r"}`)
+	store.CreateSecret("/prod/test-service/app",
+		`{"engine":"mysql","host":"my.local","username":"app","password":"initial","masterarn":"/prod/global/master"}`)
+
+	handler := NewHandler(store).RegisterDatabase(support.MySQLEngine, db, db)
+
+	rotate(t, handler, store, "/prod/test-service/app")
+	current, _ := store.GetSecretValue(context.Background(), "/prod/test-service/app", "", StageCurrent)
+	secret, _ := ParseSecret(current.Value)
+	assert.Equal(t, "app_clone", secret.Username)
+	assert.Equal(t, db.users["app_clone"], secret.Password)
+
+	rotate(t, handler, store, "/prod/test-service/app")
+	current, _ = store.GetSecretValue(context.Background(), "/prod/test-service/app", "", StageCurrent)
+	secret, _ = ParseSecret(current.Value)
+	assert.Equal(t, "app", secret.Username)
+	assert.Equal(t, db.users["app"], secret.Password)
+	assert.Equal(t, 2, db.setCalls)
+}
+
+func TestRotateStepsAreIdempotent(t *testing.T) {
+	store := NewMemoryStore()
+	db := &fakeDatabase{users: map[string]string{"admin": "initial"}}
+
+	store.CreateSecret("/prod/test-service/dbctx",
+		`{"engine":"postgres","host":"pg.local","username":"admin","password":"initial"}`)
+
+	handler := NewHandler(store).RegisterDatabase(support.PostgresDBEngine, db, db)
+	event, _ := store.StartRotation("/prod/test-service/dbctx")
+
+	for _, step := range []Step{CreateSecret, CreateSecret, SetSecret, SetSecret, TestSecret, FinishSecret, FinishSecret} {
+		event.Step = step
+		assert.Equal(t, nil, handler.Rotate(context.Background(), event), string(step))
+	}
+
+	assert.Equal(t, 1, db.setCalls)
+}
+
+func TestRotateFailsWhenTestLoginFails(t *testing.T) {
+	store := NewMemoryStore()
+	db := &fakeDatabase{users: map[string]string{"admin": "initial"}}
+
+	store.CreateSecret("/prod/test-service/dbctx",
+		`{"engine":"postgres","host":"pg.local","username":"admin","password":"initial"}`)
+
+	handler := NewHandler(store).RegisterDatabase(support.PostgresDBEngine, db, db)
+	event, _ := store.StartRotation("/prod/test-service/dbctx")
+
+	event.Step = CreateSecret
+	assert.Equal(t, nil, handler.Rotate(context.Background(), event))
+
+	// setSecret is never done - hence the database still have the old password
+	event.Step = TestSecret
+	assert.NotEqual(t, nil, handler.Rotate(context.Background(), event))
+
+	current, _ := store.GetSecretValue(context.Background(), "/prod/test-service/dbctx", "", StageCurrent)
+	assert.Contains(t, current.Value, `"password":"initial"`)
+}
+
+func TestRotateUnregisteredEngineFails(t *testing.T) {
+	store := NewMemoryStore()
+	store.CreateSecret("/prod/test-service/dbctx",
+		`{"engine":"oracle","host":"ora.local","username":"admin","password":"initial"}`)
+
+	handler := NewHandler(store)
+	event, _ := store.StartRotation("/prod/test-service/dbctx")
+
+	event.Step = CreateSecret
+	assert.Equal(t, nil, handler.Rotate(context.Background(), event))
+
+	event.Step = SetSecret
+	assert.NotEqual(t, nil, handler.Rotate(context.Background(), event))
+}
+
+func TestRandomPasswordExcludesCharacters(t *testing.T) {
+	password, err := RandomPassword(200, DefaultExcludeCharacters)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, len(password))
+	assert.NotContains(t, password, "/")
+	assert.NotContains(t, password, "@")
+	assert.NotContains(t, password, `"`)
+}
@@ -0,0 +1,107 @@
+package rotation
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/pkg/errors"
+)
+
+type secretsManagerStore struct {
+	client *secretsmanager.Client
+}
+
+// NewSecretsManagerStore creates a SecretStore that is backed by AWS Secrets Manager
+func NewSecretsManagerStore(client *secretsmanager.Client) SecretStore {
+	return &secretsManagerStore{client: client}
+}
+
+func (s *secretsManagerStore) DescribeSecret(ctx context.Context, secretID string) (*SecretDescription, error) {
+	resp, err := s.client.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: aws.String(secretID)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretDescription{
+		Name:            aws.ToString(resp.Name),
+		RotationEnabled: aws.ToBool(resp.RotationEnabled),
+		Versions:        resp.VersionIdsToStages,
+	}, nil
+}
+
+func (s *secretsManagerStore) GetSecretValue(ctx context.Context,
+	secretID string, versionID string, stage string) (*SecretVersion, error) {
+
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+	if stage != "" {
+		input.VersionStage = aws.String(stage)
+	}
+
+	resp, err := s.client.GetSecretValue(ctx, input)
+	if err != nil {
+		var resourceNotFound *types.ResourceNotFoundException
+		if errors.As(err, &resourceNotFound) {
+			return nil, ErrVersionNotFound
+		}
+
+		return nil, err
+	}
+
+	return &SecretVersion{
+		Name:      aws.ToString(resp.Name),
+		VersionID: aws.ToString(resp.VersionId),
+		Stages:    resp.VersionStages,
+		Value:     aws.ToString(resp.SecretString),
+	}, nil
+}
+
+func (s *secretsManagerStore) PutSecretValue(ctx context.Context,
+	secretID string, versionID string, value string, stages []string) error {
+
+	_, err := s.client.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:           aws.String(secretID),
+		ClientRequestToken: aws.String(versionID),
+		SecretString:       aws.String(value),
+		VersionStages:      stages,
+	})
+
+	return err
+}
+
+func (s *secretsManagerStore) UpdateSecretVersionStage(ctx context.Context,
+	secretID string, stage string, moveTo string, removeFrom string) error {
+
+	input := &secretsmanager.UpdateSecretVersionStageInput{
+		SecretId:     aws.String(secretID),
+		VersionStage: aws.String(stage),
+	}
+
+	if moveTo != "" {
+		input.MoveToVersionId = aws.String(moveTo)
+	}
+	if removeFrom != "" {
+		input.RemoveFromVersionId = aws.String(removeFrom)
+	}
+
+	_, err := s.client.UpdateSecretVersionStage(ctx, input)
+	return err
+}
+
+func (s *secretsManagerStore) GetRandomPassword(ctx context.Context, length int, exclude string) (string, error) {
+	input := &secretsmanager.GetRandomPasswordInput{PasswordLength: aws.Int64(int64(length))}
+	if exclude != "" {
+		input.ExcludeCharacters = aws.String(exclude)
+	}
+
+	resp, err := s.client.GetRandomPassword(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(resp.RandomPassword), nil
+}
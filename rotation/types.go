@@ -0,0 +1,166 @@
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+// Step is one of the four Secrets Manager rotation steps
+type Step string
+
+const (
+	// CreateSecret creates a new version of the secret with the AWSPENDING stage
+	CreateSecret Step = "createSecret"
+	// SetSecret sets the AWSPENDING secret in the database
+	SetSecret Step = "setSecret"
+	// TestSecret tests that it is possible to login using the AWSPENDING secret
+	TestSecret Step = "testSecret"
+	// FinishSecret moves the AWSCURRENT stage to the AWSPENDING version
+	FinishSecret Step = "finishSecret"
+)
+
+const (
+	// StageCurrent is the staging label of the secret version in use
+	StageCurrent = support.StageCurrent
+	// StagePending is the staging label of the secret version being rotated in
+	StagePending = support.StagePending
+	// StagePrevious is the staging label of the secret version last in use
+	StagePrevious = support.StagePrevious
+)
+
+// Event is the event that Secrets Manager sends to the rotation lambda. It is
+// possible to pass the lambda event straight into Handler.Rotate.
+type Event struct {
+	// SecretID is the name or ARN of the secret to rotate
+	SecretID string `json:"SecretId"`
+	// ClientRequestToken is the version id of the new (AWSPENDING) secret version
+	ClientRequestToken string `json:"ClientRequestToken"`
+	// Step is the rotation step to perform
+	Step Step `json:"Step"`
+}
+
+// SecretVersion is a version of a secret
+type SecretVersion struct {
+	// Name is the name of the secret
+	Name string
+	// VersionID is the unique version id
+	VersionID string
+	// Stages are the staging labels attached to this version
+	Stages []string
+	// Value is the secret string
+	Value string
+}
+
+// SecretDescription describes a secret and all of its versions
+type SecretDescription struct {
+	// Name is the name of the secret
+	Name string
+	// RotationEnabled is true if rotation is enabled on the secret
+	RotationEnabled bool
+	// Versions is the staging labels for each version id
+	Versions map[string][]string
+}
+
+// SecretStore is the set of Secrets Manager operations needed to rotate a
+// secret. Use NewSecretsManagerStore for AWS and NewMemoryStore when testing.
+type SecretStore interface {
+	// DescribeSecret describes the secret along with all versions and stages
+	DescribeSecret(ctx context.Context, secretID string) (*SecretDescription, error)
+	// GetSecretValue gets the version by versionID and / or stage. If the version is
+	// not found ErrVersionNotFound is returned.
+	GetSecretValue(ctx context.Context, secretID string, versionID string, stage string) (*SecretVersion, error)
+	// PutSecretValue creates a new version with versionID (idempotent) and stages
+	PutSecretValue(ctx context.Context, secretID string, versionID string, value string, stages []string) error
+	// UpdateSecretVersionStage moves the stage to moveTo version id and removes it
+	// from the removeFrom version id. Either may be empty.
+	UpdateSecretVersionStage(ctx context.Context, secretID string, stage string, moveTo string, removeFrom string) error
+	// GetRandomPassword generates a new password
+	GetRandomPassword(ctx context.Context, length int, exclude string) (string, error)
+}
+
+// ErrVersionNotFound is returned by the SecretStore when a version is not found
+var ErrVersionNotFound = errors.New("secret version not found")
+
+// Secret is a rotation template secret as specified by the templates in the
+// support package, e.g. support.SecretsManagerRDSPostgreSQLRotationSingleUser.
+type Secret struct {
+	support.SecretsManagerBaseTemplate
+	// MasterArn is set when multi user rotation and is the ARN of the master secret
+	MasterArn string `json:"masterarn,omitempty"`
+	// raw is the complete JSON document so no other key is lost when rotating
+	raw map[string]interface{}
+}
+
+// ParseSecret parses a secret string in any of the support package template formats.
+// Numeric values (e.g. port) are accepted as well as strings.
+func ParseSecret(value string) (*Secret, error) {
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, errors.Wrapf(err, "Secret is not a JSON rotation template")
+	}
+
+	str := func(key string) string {
+		if v, ok := raw[key]; ok && v != nil {
+			if s, ok := v.(string); ok {
+				return s
+			}
+			return fmt.Sprintf("%v", v)
+		}
+		return ""
+	}
+
+	secret := &Secret{raw: raw, MasterArn: str("masterarn")}
+	secret.Engine = support.ASMEngine(str("engine"))
+	secret.Host = str("host")
+	secret.Username = str("username")
+	secret.Password = str("password")
+	secret.DbName = str("dbname")
+	secret.Port = str("port")
+
+	return secret, nil
+}
+
+// String renders the secret as JSON. All keys that was present in the parsed
+// document is retained and username & password are updated.
+func (s *Secret) String() string {
+	raw := map[string]interface{}{}
+	for key, value := range s.raw {
+		raw[key] = value
+	}
+
+	raw["username"] = s.Username
+	raw["password"] = s.Password
+
+	data, _ := json.Marshal(raw)
+	return string(data)
+}
+
+// IsMultiUser returns true if the secret is a multi user template (masterarn set)
+func (s *Secret) IsMultiUser() bool { return s.MasterArn != "" }
+
+// SetPasswordRequest is passed to the PasswordSetter
+type SetPasswordRequest struct {
+	// Pending is the secret (AWSPENDING) of which the password shall be set
+	Pending *Secret
+	// Current is the secret (AWSCURRENT) currently in use
+	Current *Secret
+	// Master is the master secret when multi user rotation, otherwise nil
+	Master *Secret
+}
+
+// PasswordSetter sets the password in the database. This is database specific.
+type PasswordSetter interface {
+	// SetPassword sets the password of the pending user. When single user, use the
+	// current secret to login and when multi user, use the master secret to login.
+	SetPassword(ctx context.Context, request SetPasswordRequest) error
+}
+
+// LoginTester tests a login to the database. This is database specific.
+type LoginTester interface {
+	// TestLogin tries to login using the secret and returns an error if not possible
+	TestLogin(ctx context.Context, secret *Secret) error
+}
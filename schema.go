@@ -0,0 +1,77 @@
+package ssm
+
+import (
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/schema"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// SchemasWithOpts generates a JSON Schema for each JSON valued parameter or secret in
+// the in param struct, keyed by the remote name. Struct fields and fields that reads a
+// jsonkey are JSON valued.
+func (s *Serializer) SchemasWithOpts(v interface{},
+	filter *support.FieldFilters) (map[string]*schema.Schema, error) {
+
+	node, err := s.parseTagged(v)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := map[string]*schema.Schema{}
+	for name, prm := range schema.Parameters(node, filter, s.usageTags(s.usageOrDefault())...) {
+		schemas[name] = prm.Schema
+	}
+
+	return schemas, nil
+}
+
+// UseSchemaValidation enables, or disables, validation of JSON valued parameters and
+// secrets against their schema before Marshal writes them. Fields that do not match are
+// not written and are returned with the error in the Marshal result.
+func (s *Serializer) UseSchemaValidation(validate bool) *Serializer {
+	s.validate = validate
+	return s
+}
+
+// validateSchemas validates all JSON valued parameters in the tree. It returns the
+// fields that failed and a filter that excludes those.
+func (s *Serializer) validateSchemas(node *parser.StructNode, filter *support.FieldFilters,
+	usage []Usage) (map[string]support.FullNameField, *support.FieldFilters) {
+
+	invalid := map[string]support.FullNameField{}
+	filter = copyFilters(filter)
+
+	for name, prm := range schema.Parameters(node, filter, s.usageTags(usage)...) {
+		err := prm.Validate()
+		if err == nil {
+			continue
+		}
+
+		for _, n := range prm.Nodes {
+			invalid[n.FqName] = support.FullNameField{
+				LocalName:  n.FqName,
+				RemoteName: name,
+				Field:      n.Field,
+				Value:      n.Value,
+				Error:      err,
+			}
+
+			filter.Exclude(n.FqName)
+		}
+	}
+
+	return invalid, filter
+}
+
+// usageTags returns the tag names of the usage
+func (s *Serializer) usageTags(usage []Usage) []string {
+	tags := []string{}
+	if _, found := find(usage, UsePms); found {
+		tags = append(tags, "pms")
+	}
+	if _, found := find(usage, UseAsm); found {
+		tags = append(tags, "asm")
+	}
+
+	return tags
+}
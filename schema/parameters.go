@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"reflect"
+
+	"github.com/mariotoffia/ssm/internal/common"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/pkg/errors"
+)
+
+// Parameter is a JSON valued parameter or secret
+type Parameter struct {
+	// Name is the remote name
+	Name string
+	// Tag is the tag that backs the parameter, e.g. pms or asm
+	Tag string
+	// Nodes is the fields that are stored in the parameter. This is a single node
+	// for a struct field and one or more when fields reads a jsonkey.
+	Nodes []*parser.StructNode
+	// Schema is the schema of the JSON document
+	Schema *Schema
+}
+
+// Parameters creates the schema for each JSON valued parameter in the node tree, keyed
+// by the remote name. Parameters with a scalar value have no schema. The tags are the
+// tags to include, e.g. pms and asm.
+func Parameters(node *parser.StructNode, filter *support.FieldFilters, tags ...string) map[string]*Parameter {
+	if filter == nil {
+		filter = support.NewFilters()
+	}
+
+	params := map[string]*Parameter{}
+
+	for _, tag := range tags {
+		groups := map[string][]*parser.StructNode{}
+		parser.NodesToParameterGroups(node, groups, filter, []string{tag})
+
+		for name, group := range groups {
+			var s *Schema
+
+			if common.IsJSONKeyGroup(group, tag) {
+				s = jsonKeySchema(group, tag)
+			} else if isJSONField(group[0]) {
+				s = FromType(group[0].Field.Type)
+			} else {
+				continue
+			}
+
+			s.Schema = Draft
+			s.Title = name
+			s.Description = group[0].Tag[tag].GetNamed()["description"]
+
+			params[name] = &Parameter{Name: name, Tag: tag, Nodes: group, Schema: s}
+		}
+	}
+
+	return params
+}
+
+// Validate validates the current values of the fields against the schema, i.e. the
+// document that Marshal would write.
+func (p *Parameter) Validate() error {
+	var (
+		document string
+		err      error
+	)
+
+	if common.IsJSONKeyGroup(p.Nodes, p.Tag) {
+		document, err = common.MergeJSONKeys("", p.Nodes, p.Tag)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to render %s", p.Name)
+		}
+	} else {
+		document = common.GetStringValueFromField(p.Nodes[0])
+	}
+
+	if err := p.Schema.Validate(document); err != nil {
+		return errors.Wrapf(err, "%s do not match its schema", p.Name)
+	}
+
+	return nil
+}
+
+// isJSONField returns true when the field is stored as a JSON document
+func isJSONField(node *parser.StructNode) bool {
+	return node.Field.Type.Kind() == reflect.Struct && node.Field.Type != support.RotatingSecretType
+}
+
+// jsonKeySchema creates an object schema where each jsonkey is a property. JSON pointer
+// keys creates nested objects. The keys are not required since Unmarshal leaves the
+// field untouched when missing.
+func jsonKeySchema(group []*parser.StructNode, tag string) *Schema {
+	root := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+
+	for _, node := range group {
+		tokens := common.JSONKeyTokens(common.JSONKey(node, tag))
+
+		current := root
+		for _, token := range tokens[:len(tokens)-1] {
+			next, ok := current.Properties[token]
+			if !ok || next.Properties == nil {
+				next = &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+				current.Properties[token] = next
+			}
+
+			current = next
+		}
+
+		prop := FromType(node.Field.Type)
+		if pattern, ok := node.Field.Tag.Lookup(PatternTag); ok {
+			prop.Pattern = pattern
+		}
+
+		current.Properties[tokens[len(tokens)-1]] = prop
+	}
+
+	return root
+}
@@ -0,0 +1,189 @@
+// Package schema generates JSON Schemas (draft-07) for parameters and secrets that are
+// stored as JSON documents, i.e. struct fields and fields that reads a jsonkey. The
+// schemas are derived from the go type, the json tags and pattern tags. A value may be
+// validated against the schema before it is written to the remote storage.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema version of the generated schemas
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// PatternTag is the struct tag with a regexp that a string value must match, e.g.
+// Host string `json:"host" pattern:"^[a-z.]+$"`
+const PatternTag = "pattern"
+
+// Types is the JSON types of a value. It is rendered as a single string when one type.
+type Types []string
+
+// Schema is the subset of JSON Schema used to describe go values
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// MarshalJSON renders a single type as a string and several as an array
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts both a single type and an array of types
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*t = list
+	return nil
+}
+
+// JSON renders the schema as indented JSON
+func (s *Schema) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// FromType creates the schema of the go type as encoding/json would render it. Fields
+// without omitempty that are not pointers are required. Recursive types are allowed to
+// be any value where they recurse.
+func FromType(t reflect.Type) *Schema {
+	return fromType(t, map[reflect.Type]bool{})
+}
+
+func fromType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := fromType(t.Elem(), visiting)
+		if len(s.Type) > 0 {
+			s.Type = append(s.Type, "null")
+		}
+
+		return s
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json renders []byte as base64
+			return &Schema{Type: Types{"string", "null"}}
+		}
+
+		return &Schema{Type: Types{"array", "null"}, Items: fromType(t.Elem(), visiting)}
+	case reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: fromType(t.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: fromType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{}
+		}
+
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+		addFields(s, t, visiting)
+
+		return s
+	}
+
+	// interface{} and others may be any value
+	return &Schema{}
+}
+
+// addFields adds the exported fields as properties, embedded structs without a json
+// name are flattened as encoding/json does.
+func addFields(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, opts := parseJSONTag(f.Tag.Get("json"))
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, visiting)
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := fromType(f.Type, visiting)
+		if pattern, ok := f.Tag.Lookup(PatternTag); ok {
+			prop.Pattern = pattern
+		}
+
+		if strings.Contains(opts, "string") {
+			prop.Type = Types{"string"}
+		}
+
+		s.Properties[name] = prop
+
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func parseJSONTag(tag string) (string, string) {
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], tag[idx+1:]
+	}
+
+	return tag, ""
+}
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/internal/pms"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type dbContext struct {
+	Base
+	Host     string            `json:"host" pattern:"^[a-z.]+$"`
+	Port     int               `json:"port,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Enabled  *bool             `json:"enabled"`
+	Created  time.Time         `json:"created"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Replicas []string          `json:"replicas,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+type dbConfig struct {
+	Host string `json:"host" pattern:"^[a-z.]+$"`
+	Port int    `json:"port,omitempty"`
+}
+
+type schemaTest struct {
+	Name string                 `pms:"name"`
+	Db   dbConfig               `pms:"db"`
+	User string                 `asm:"creds, jsonkey=user" pattern:"^[a-z]+$"`
+	Pwd  string                 `asm:"creds, jsonkey=/nested/password"`
+	Rot  support.RotatingSecret `asm:"rotating"`
+}
+
+func TestFromTypeStruct(t *testing.T) {
+	s := FromType(reflect.TypeOf(dbContext{}))
+
+	assert.Equal(t, Types{"object"}, s.Type)
+	assert.Equal(t, []string{"id", "host", "ratio", "created"}, s.Required)
+	assert.Equal(t, Types{"string"}, s.Properties["id"].Type)
+	assert.Equal(t, "^[a-z.]+$", s.Properties["host"].Pattern)
+	assert.Equal(t, Types{"integer"}, s.Properties["port"].Type)
+	assert.Equal(t, Types{"number"}, s.Properties["ratio"].Type)
+	assert.Equal(t, Types{"boolean", "null"}, s.Properties["enabled"].Type)
+	assert.Equal(t, "date-time", s.Properties["created"].Format)
+	assert.Equal(t, Types{"string"}, s.Properties["labels"].AdditionalProperties.Type)
+	assert.Equal(t, Types{"string"}, s.Properties["replicas"].Items.Type)
+	assert.NotContains(t, s.Properties, "Ignored")
+	assert.NotContains(t, s.Properties, "internal")
+}
+
+func TestFromTypeRecursive(t *testing.T) {
+	type node struct {
+		Next *node `json:"next"`
+	}
+
+	s := FromType(reflect.TypeOf(node{}))
+	assert.Equal(t, &Schema{}, s.Properties["next"])
+}
+
+func TestSchemaJSON(t *testing.T) {
+	data, err := (&Schema{Type: Types{"string", "null"}, Items: &Schema{Type: Types{"integer"}}}).JSON()
+	assert.Equal(t, nil, err)
+	assert.Contains(t, string(data), `"type": [`)
+	assert.Contains(t, string(data), `"type": "integer"`)
+
+	var s Schema
+	assert.Equal(t, nil, json.Unmarshal(data, &s))
+	assert.Equal(t, Types{"string", "null"}, s.Type)
+	assert.Equal(t, Types{"integer"}, s.Items.Type)
+}
+
+func TestValidate(t *testing.T) {
+	s := FromType(reflect.TypeOf(dbContext{}))
+
+	valid := `{"id":"1","host":"db.local","ratio":1,"enabled":null,"created":"2020-01-01T00:00:00Z","labels":{"a":"b"}}`
+	assert.Equal(t, nil, s.Validate(valid))
+
+	err := s.Validate(`{"id":"1","host":"DB","ratio":1,"enabled":true,"created":""}`)
+	assert.EqualError(t, err, `/host: "DB" do not match pattern ^[a-z.]+$`)
+
+	err = s.Validate(`{"id":"1","host":"db","ratio":"1","enabled":true,"created":""}`)
+	assert.EqualError(t, err, `/ratio: expected number but got string`)
+
+	err = s.Validate(`{"id":"1","host":"db","ratio":1,"enabled":true,"created":"","port":1.5}`)
+	assert.EqualError(t, err, `/port: expected integer but got number`)
+
+	err = s.Validate(`{"id":"1","host":"db","enabled":true,"created":""}`)
+	assert.EqualError(t, err, `/: missing required property ratio`)
+
+	err = s.Validate(`{"id":"1","host":"db","ratio":1,"enabled":true,"created":"","labels":{"a":1}}`)
+	assert.EqualError(t, err, `/labels/a: expected string but got integer`)
+
+	assert.Error(t, s.Validate(`{"id":`))
+}
+
+func parseSchemaTest(t *testing.T, v *schemaTest) *parser.StructNode {
+	node, err := parser.New("test-service", "prod", "").
+		RegisterTagParser("pms", pms.NewTagParser()).
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(reflect.ValueOf(v))
+
+	assert.Equal(t, nil, err)
+	return node
+}
+
+func TestParametersOnlyJSONValued(t *testing.T) {
+	params := Parameters(parseSchemaTest(t, &schemaTest{}), nil, "pms", "asm")
+
+	assert.Equal(t, 2, len(params))
+
+	db := params["/prod/test-service/db"]
+	assert.Equal(t, "pms", db.Tag)
+	assert.Equal(t, Draft, db.Schema.Schema)
+	assert.Equal(t, "/prod/test-service/db", db.Schema.Title)
+	assert.Equal(t, "^[a-z.]+$", db.Schema.Properties["host"].Pattern)
+
+	creds := params["/prod/test-service/creds"]
+	assert.Equal(t, "asm", creds.Tag)
+	assert.Equal(t, 2, len(creds.Nodes))
+	assert.Equal(t, "^[a-z]+$", creds.Schema.Properties["user"].Pattern)
+	assert.Equal(t, Types{"string"}, creds.Schema.Properties["nested"].Properties["password"].Type)
+	assert.Equal(t, 0, len(creds.Schema.Required))
+}
+
+func TestParametersHonorsFilter(t *testing.T) {
+	params := Parameters(parseSchemaTest(t, &schemaTest{}), support.NewFilters().Exclude("Db"), "pms", "asm")
+
+	assert.Equal(t, 1, len(params))
+	assert.Contains(t, params, "/prod/test-service/creds")
+}
+
+func TestParameterValidate(t *testing.T) {
+	v := &schemaTest{User: "nisse", Pwd: "s3cr3t"}
+	v.Db.Host = "db.local"
+
+	params := Parameters(parseSchemaTest(t, v), nil, "pms", "asm")
+	assert.Equal(t, nil, params["/prod/test-service/db"].Validate())
+	assert.Equal(t, nil, params["/prod/test-service/creds"].Validate())
+
+	v.Db.Host = "DB"
+	v.User = "Nisse"
+
+	params = Parameters(parseSchemaTest(t, v), nil, "pms", "asm")
+	assert.EqualError(t, params["/prod/test-service/db"].Validate(),
+		`/prod/test-service/db do not match its schema: /host: "DB" do not match pattern ^[a-z.]+$`)
+	assert.Error(t, params["/prod/test-service/creds"].Validate())
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Validate validates the JSON document against the schema. The error describes the
+// first violation found with a JSON pointer to the offending value.
+func (s *Schema) Validate(document string) error {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(document)))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return errors.Wrap(err, "Value is not a valid JSON document")
+	}
+
+	return s.validate(v, "")
+}
+
+func (s *Schema) validate(v interface{}, path string) error {
+	if len(s.Type) > 0 && !s.hasType(jsonType(v)) {
+		return errors.Errorf("%s: expected %s but got %s", pointer(path), strings.Join(s.Type, " or "), jsonType(v))
+	}
+
+	switch value := v.(type) {
+	case string:
+		if s.Pattern == "" {
+			return nil
+		}
+
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "%s: invalid pattern %s", pointer(path), s.Pattern)
+		}
+
+		if !re.MatchString(value) {
+			return errors.Errorf("%s: %q do not match pattern %s", pointer(path), value, s.Pattern)
+		}
+	case []interface{}:
+		if s.Items == nil {
+			return nil
+		}
+
+		for i, item := range value {
+			if err := s.Items.validate(item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				return errors.Errorf("%s: missing required property %s", pointer(path), name)
+			}
+		}
+
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			prop, ok := s.Properties[key]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+
+			if prop == nil {
+				continue
+			}
+
+			if err := prop.validate(value[key], path+"/"+escapeToken(key)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) hasType(tp string) bool {
+	for _, t := range s.Type {
+		// All integers are numbers
+		if t == tp || (t == "number" && tp == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonType(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if !strings.ContainsAny(value.String(), ".eE") {
+			return "integer"
+		}
+
+		return "number"
+	case []interface{}:
+		return "array"
+	}
+
+	return "object"
+}
+
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	return path
+}
+
+func escapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,41 @@
+package ssm
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/support"
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaDb struct {
+	Host string `json:"host" pattern:"^[a-z.]+$"`
+	Port int    `json:"port"`
+}
+
+type schemaTest struct {
+	Name string   `pms:"name"`
+	Db   schemaDb `pms:"db"`
+	User string   `asm:"creds, jsonkey=user"`
+}
+
+func TestSchemasUsesSerializerNames(t *testing.T) {
+	s := NewSsmSerializer("dev", "my-service")
+
+	schemas, err := s.SchemasWithOpts(&schemaTest{}, NoFilter)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 2, len(schemas))
+	assert.Equal(t, "^[a-z.]+$", schemas["/dev/my-service/db"].Properties["host"].Pattern)
+	assert.Contains(t, schemas["/dev/my-service/creds"].Properties, "user")
+}
+
+func TestMarshalSkipsFieldsNotMatchingSchema(t *testing.T) {
+	s := NewSsmSerializer("dev", "my-service").UseSchemaValidation(true)
+
+	v := &schemaTest{Db: schemaDb{Host: "DB", Port: 5432}}
+	invalid := s.MarshalWithOpts(v, support.NewFilters().Exclude("Name"), OnlyPms)
+
+	assert.Equal(t, 1, len(invalid))
+	assert.Equal(t, "/dev/my-service/db", invalid["Db"].RemoteName)
+	assert.Contains(t, invalid["Db"].Error.Error(), `/host: "DB" do not match pattern ^[a-z.]+$`)
+}
@@ -1,12 +1,9 @@
 package ssm
 
 import (
-	"reflect"
-
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
 	"github.com/mariotoffia/ssm/internal/asm"
-	"github.com/mariotoffia/ssm/internal/pms"
 	"github.com/mariotoffia/ssm/parser"
 	"github.com/mariotoffia/ssm/report"
 	"github.com/mariotoffia/ssm/support"
@@ -46,6 +43,16 @@ type Serializer struct {
 	usage     []Usage
 	parser    map[string]parser.TagParser
 	prefix    string
+	// contributors renders the report parameters of custom tags
+	contributors map[string]report.Contributor
+	// concurrency is the max number of parallel requests to secrets manager
+	concurrency int
+	// recoveryWindow is the number of days a deleted secret may be restored
+	recoveryWindow int64
+	// asm is created once and reused so the secrets manager client is reused
+	asm *asm.Serializer
+	// validate is set when JSON values are validated against their schema on Marshal
+	validate bool
 }
 
 // NewSsmSerializer creates a new serializer with default aws.Config
@@ -55,6 +62,8 @@ func NewSsmSerializer(env string, service string) *Serializer {
 		service: service,
 		tier:    types.ParameterTierStandard,
 		parser:  map[string]parser.TagParser{},
+
+		contributors: map[string]report.Contributor{},
 	}
 }
 
@@ -68,6 +77,8 @@ func NewSsmSerializerFromConfig(env string, service string, config aws.Config) *
 		config:    config,
 		hasconfig: true,
 		parser:    map[string]parser.TagParser{},
+
+		contributors: map[string]report.Contributor{},
 	}
 }
 
@@ -78,6 +89,13 @@ func (s *Serializer) UseTagParser(tag string, parser parser.TagParser) *Serializ
 	return s
 }
 
+// UseReportContributor registers a contributor that renders the report parameters
+// of fields with the custom tag. Register the tag parser using UseTagParser.
+func (s *Serializer) UseReportContributor(tag string, contributor report.Contributor) *Serializer {
+	s.contributors[tag] = contributor
+	return s
+}
+
 // UsePrefix acts as a default prefix if no prefix is specified in the tag.
 //
 // Prefix operates under two modes: _Local_ and _Global_.
@@ -110,6 +128,143 @@ func (s *Serializer) SetTier(tier types.ParameterTier) *Serializer {
 	return s
 }
 
+// SetConcurrency sets the max number of parallel requests towards the secrets
+// manager for secrets that can not be fetched in batches (e.g. when vid or vs is
+// specified on the tag). By default asm.DefaultConcurrency is used.
+func (s *Serializer) SetConcurrency(concurrency int) *Serializer {
+	s.concurrency = concurrency
+	if s.asm != nil {
+		s.asm.SetConcurrency(concurrency)
+	}
+
+	return s
+}
+
+// SetRecoveryWindow sets the number of days (7 - 30) a deleted secret may be restored
+// using Restore. By default secrets are deleted without any recovery (zero days). It is
+// possible to override this per field using the recovery tag parameter on asm tags.
+func (s *Serializer) SetRecoveryWindow(days int64) error {
+	if err := asm.ValidateRecoveryWindow(days); err != nil {
+		return err
+	}
+
+	s.recoveryWindow = days
+	if s.asm != nil {
+		return s.asm.SetRecoveryWindow(days)
+	}
+
+	return nil
+}
+
+// Restore restores the secrets manager secrets, denoted by asm tags, in the in param
+// struct that has been deleted with a recovery window. It returns a map contains fields
+// that where failed to be restored.
+func (s *Serializer) Restore(v interface{}) (map[string]support.FullNameField, error) {
+	return s.restore(v, nil)
+}
+
+// RestoreWithOpts is the same as Restore but accepts a set of inclusion & exclusion
+// filters to select which secrets to restore.
+func (s *Serializer) RestoreWithOpts(v interface{},
+	filter *support.FieldFilters) (map[string]support.FullNameField, error) {
+	return s.restore(v, filter)
+}
+
+// Versions lists all versions, along with their staging labels, of each secrets
+// manager secret denoted by asm tags in the in param struct. The map is keyed by
+// the local field name.
+func (s *Serializer) Versions(v interface{}) (map[string][]support.SecretVersion, error) {
+	return s.versions(v, nil)
+}
+
+// VersionsWithOpts is the same as Versions but accepts a set of inclusion & exclusion
+// filters to select which secrets to include.
+func (s *Serializer) VersionsWithOpts(v interface{},
+	filter *support.FieldFilters) (map[string][]support.SecretVersion, error) {
+	return s.versions(v, filter)
+}
+
+// MoveStage moves the staging label stage onto the version having the fromStage label,
+// for each secret denoted by asm tags in the in param struct. For example, moving
+// AWSCURRENT from CANARY promotes the canary version. It returns a map contains fields
+// that where failed to be moved.
+func (s *Serializer) MoveStage(v interface{},
+	stage string, fromStage string) (map[string]support.FullNameField, error) {
+	return s.moveStage(v, nil, stage, fromStage)
+}
+
+// MoveStageWithOpts is the same as MoveStage but accepts a set of inclusion & exclusion
+// filters to select which secrets to move the stage on.
+func (s *Serializer) MoveStageWithOpts(v interface{}, filter *support.FieldFilters,
+	stage string, fromStage string) (map[string]support.FullNameField, error) {
+	return s.moveStage(v, filter, stage, fromStage)
+}
+
+// RemoveStage removes the staging label stage from each secret denoted by asm tags in
+// the in param struct. It returns a map contains fields that where failed to be removed.
+func (s *Serializer) RemoveStage(v interface{}, stage string) (map[string]support.FullNameField, error) {
+	return s.moveStage(v, nil, stage, "")
+}
+
+// MarshalStaged writes the asm fields of the in param struct as a new version with only
+// the staging label stage attached. AWSCURRENT is left untouched, hence readers using the
+// stage (vs tag) gets the new value while all others still get the current. Use MoveStage
+// to promote the staged version. The secrets must exist. It returns a map contains fields
+// that where failed to be written.
+func (s *Serializer) MarshalStaged(v interface{}, stage string) (map[string]support.FullNameField, error) {
+	return s.marshalStaged(v, nil, stage)
+}
+
+// MarshalStagedWithOpts is the same as MarshalStaged but accepts a set of inclusion &
+// exclusion filters to select which secrets to write.
+func (s *Serializer) MarshalStagedWithOpts(v interface{}, filter *support.FieldFilters,
+	stage string) (map[string]support.FullNameField, error) {
+	return s.marshalStaged(v, filter, stage)
+}
+
+// LabelVersions attaches the label onto the current version of each parameter store
+// parameter, denoted by pms tags, in the in param struct. If the label is already
+// attached to another version it is moved. It returns a map contains fields that
+// where failed to be labelled.
+func (s *Serializer) LabelVersions(v interface{}, label string) (map[string]support.FullNameField, error) {
+	return s.labelVersions(v, nil, label)
+}
+
+// LabelVersionsWithOpts is the same as LabelVersions but accepts a set of inclusion &
+// exclusion filters to select which parameters to label.
+func (s *Serializer) LabelVersionsWithOpts(v interface{},
+	filter *support.FieldFilters, label string) (map[string]support.FullNameField, error) {
+	return s.labelVersions(v, filter, label)
+}
+
+// History returns all versions, oldest first, of each parameter store parameter
+// denoted by pms tags in the in param struct. The map is keyed by the local field name.
+func (s *Serializer) History(v interface{}) (map[string][]support.ParameterVersion, error) {
+	return s.history(v, nil)
+}
+
+// HistoryWithOpts is the same as History but accepts a set of inclusion & exclusion
+// filters to select which parameters to include.
+func (s *Serializer) HistoryWithOpts(v interface{},
+	filter *support.FieldFilters) (map[string][]support.ParameterVersion, error) {
+	return s.history(v, filter)
+}
+
+// Rollback writes the value of the version having the label as a new version for each
+// parameter store parameter, denoted by pms tags, in the in param struct. Use
+// LabelVersions to label a known good state to rollback to. It returns a map contains
+// fields that where failed to be rolled back.
+func (s *Serializer) Rollback(v interface{}, label string) (map[string]support.FullNameField, error) {
+	return s.rollback(v, nil, label)
+}
+
+// RollbackWithOpts is the same as Rollback but accepts a set of inclusion & exclusion
+// filters to select which parameters to rollback.
+func (s *Serializer) RollbackWithOpts(v interface{},
+	filter *support.FieldFilters, label string) (map[string]support.FullNameField, error) {
+	return s.rollback(v, filter, label)
+}
+
 // Delete creates the in param struct pointer (and sub struct as well).
 // It will search the fields that are denoted with pms and asm
 // with data from the Systems Manager. It tries to delete all keys. It returns
@@ -147,7 +302,8 @@ func (s *Serializer) AdvDeleteWithOpts(v interface{},
 // Unmarshal creates the in param struct pointer (and sub struct as well).
 // It will populate the fields that are denoted with pms and asm
 // with data from the Systems Manager. It returns a map contains fields that
-// where requested but not set.
+// where requested but not set. If a secret is not set since it is scheduled for
+// deletion, the field Error is set to support.ErrScheduledForDeletion.
 func (s *Serializer) Unmarshal(v interface{}) (map[string]support.FullNameField, error) {
 	inv, _, err := s.unmarshal(v, nil, nil)
 	return inv, err
@@ -213,7 +369,10 @@ func (s *Serializer) AdvMarshalWithOpts(v interface{},
 }
 
 // ReportWithOpts generates a struct based and JSON based report of the in param type
-// or actual struct value to have default values generated.
+// or actual struct value to have default values generated. The names are rendered
+// using the serializer env, service and prefix. If values is set, the field values
+// are part of the report. Fields with a custom tag are reported by the contributor
+// registered using UseReportContributor.
 // The JSON report is on the following example format:
 // "parameters": [
 //	  {
@@ -231,16 +390,15 @@ func (s *Serializer) ReportWithOpts(v interface{},
 	filter *support.FieldFilters,
 	values bool) (*report.Report, string, error) {
 
-	tp := reflect.ValueOf(v)
-	node, err := parser.New("test-service", "prod", s.prefix).
-		RegisterTagParser("asm", asm.NewTagParser()).
-		RegisterTagParser("pms", pms.NewTagParser()).
-		Parse(tp)
-
-	if err != nil {
-		return nil, "", err
-	}
+	rpt, json, _, err := s.report(v, filter, values, nil)
+	return rpt, json, err
+}
 
-	reporter := report.NewWithTier(s.tier)
-	return reporter.RenderReport(node, filter, true)
+// AdvReportWithOpts is the same function as the ReportWithOpts but it also accepts a
+// set of usage directives (for example only report PMS parameters) and returns the tree
+// of parsed node to be post-processed.
+func (s *Serializer) AdvReportWithOpts(v interface{},
+	filter *support.FieldFilters, values bool,
+	usage []Usage) (*report.Report, string, *parser.StructNode, error) {
+	return s.report(v, filter, values, usage)
 }
@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// AlgorithmPassphrase is AES-256-GCM with a key derived from a passphrase using PBKDF2-HMAC-SHA256
+	AlgorithmPassphrase = "pbkdf2-sha256+aes-256-gcm"
+	// AlgorithmKey is AES-256-GCM with a key generated by GenerateKey
+	AlgorithmKey = "aes-256-gcm"
+	// KeyPrefix is the prefix of keys generated by GenerateKey
+	KeyPrefix = "SSM-SNAPSHOT-KEY-"
+	// DefaultIterations is the number of PBKDF2 iterations when deriving a key from a passphrase
+	DefaultIterations = 600000
+
+	keyLength  = 32
+	saltLength = 16
+)
+
+// ErrInvalidKey is returned when decrypting using the wrong passphrase or key
+var ErrInvalidKey = errors.New("invalid snapshot key or passphrase")
+
+// GenerateKey generates a random key that may be used instead of a passphrase. It
+// is self-contained, i.e. it includes the KeyPrefix, and must be kept as a secret.
+func GenerateKey() (string, error) {
+	key := make([]byte, keyLength)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// newEncryption creates the encryption settings for the key. If the key is a
+// generated key it is used as is, otherwise it is treated as a passphrase.
+func newEncryption(key string) (*Encryption, error) {
+	if strings.HasPrefix(key, KeyPrefix) {
+		return &Encryption{Algorithm: AlgorithmKey}, nil
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	return &Encryption{Algorithm: AlgorithmPassphrase,
+		Salt: base64.StdEncoding.EncodeToString(salt), Iterations: DefaultIterations}, nil
+}
+
+// aead creates the AES-256-GCM cipher for the encryption settings and key
+func (e *Encryption) aead(key string) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, errors.New("A key or passphrase is required to encrypt / decrypt secure values")
+	}
+
+	var raw []byte
+
+	switch e.Algorithm {
+	case AlgorithmKey:
+		if !strings.HasPrefix(key, KeyPrefix) {
+			return nil, errors.Errorf("Snapshot is encrypted using a generated key, not a passphrase")
+		}
+
+		var err error
+		if raw, err = base64.RawURLEncoding.DecodeString(strings.TrimPrefix(key, KeyPrefix)); err != nil || len(raw) != keyLength {
+			return nil, ErrInvalidKey
+		}
+	case AlgorithmPassphrase:
+		salt, err := base64.StdEncoding.DecodeString(e.Salt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid salt in snapshot")
+		}
+
+		raw = pbkdf2.Key([]byte(key), salt, e.Iterations, keyLength, sha256.New)
+	default:
+		return nil, errors.Errorf("Unsupported snapshot encryption algorithm %s", e.Algorithm)
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the value and returns base64 of nonce and cipher text
+func seal(aead cipher.AEAD, value string, name string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	// The name is authenticated so values can not be swapped between parameters
+	data := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// open decrypts a value produced by seal
+func open(aead cipher.AEAD, value string, name string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", errors.Errorf("Invalid encrypted value for %s", name)
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", ErrInvalidKey
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,155 @@
+// Package snapshot is a versioned file format of a complete config set, i.e. all
+// parameters and secrets a struct refers to. Secure values are encrypted.
+package snapshot
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/pkg/errors"
+)
+
+// FormatVersion is the current snapshot format version
+const FormatVersion = 1
+
+// Snapshot is all parameters and secrets, including values, of a config set
+type Snapshot struct {
+	// Version is the format version
+	Version int `json:"version"`
+	// Env is the environment the snapshot was exported from
+	Env string `json:"env"`
+	// Service is the service the snapshot was exported from
+	Service string `json:"service"`
+	// Created is when the snapshot was exported
+	Created time.Time `json:"created"`
+	// Encryption is set when any value is encrypted
+	Encryption *Encryption `json:"encryption,omitempty"`
+	// Parameters is the parameters and secrets. All parameters with a SecureString
+	// value type have an encrypted value.
+	Parameters []report.Parameter `json:"parameters"`
+}
+
+// Encryption describes how the secure values are encrypted
+type Encryption struct {
+	// Algorithm is either AlgorithmPassphrase or AlgorithmKey
+	Algorithm string `json:"algorithm"`
+	// Salt is the base64 encoded PBKDF2 salt when AlgorithmPassphrase
+	Salt string `json:"salt,omitempty"`
+	// Iterations is the number of PBKDF2 iterations when AlgorithmPassphrase
+	Iterations int `json:"iterations,omitempty"`
+}
+
+// IsSecure returns true if the parameter value is encrypted in the snapshot
+func IsSecure(prm *report.Parameter) bool {
+	return prm.ValueType == "SecureString"
+}
+
+// Write encrypts all secure values using the key (a passphrase or a key from
+// GenerateKey) and writes the snapshot as JSON. The snapshot itself is not altered.
+func (s *Snapshot) Write(w io.Writer, key string) error {
+	out := *s
+	out.Version = FormatVersion
+	out.Encryption = nil
+	out.Parameters = make([]report.Parameter, len(s.Parameters))
+	copy(out.Parameters, s.Parameters)
+
+	// The key is derived once, on first secure value, since PBKDF2 is slow by design
+	var aead cipher.AEAD
+	for i := range out.Parameters {
+		prm := &out.Parameters[i]
+		if !IsSecure(prm) {
+			continue
+		}
+
+		if aead == nil {
+			enc, err := newEncryption(key)
+			if err != nil {
+				return err
+			}
+
+			if aead, err = enc.aead(key); err != nil {
+				return err
+			}
+
+			out.Encryption = enc
+		}
+
+		var err error
+		if prm.Value, err = seal(aead, prm.Value, prm.Name); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&out)
+}
+
+// Read reads a snapshot written by Write and decrypts all secure values using the key.
+func Read(r io.Reader, key string) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, errors.Wrapf(err, "Failed to read snapshot")
+	}
+
+	if s.Version != FormatVersion {
+		return nil, errors.Errorf("Unsupported snapshot version %d", s.Version)
+	}
+
+	for i := range s.Parameters {
+		if err := typedDetails(&s.Parameters[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Encryption == nil {
+		return &s, nil
+	}
+
+	aead, err := s.Encryption.aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range s.Parameters {
+		prm := &s.Parameters[i]
+		if !IsSecure(prm) {
+			continue
+		}
+
+		if prm.Value, err = open(aead, prm.Value, prm.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &s, nil
+}
+
+// typedDetails converts the JSON decoded details into report.PmsParameterDetails or
+// report.AsmParameterDetails depending on the parameter type.
+func typedDetails(prm *report.Parameter) error {
+	if prm.Details == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(prm.Details)
+	if err != nil {
+		return err
+	}
+
+	switch prm.Type {
+	case report.ParameterStore:
+		var details report.PmsParameterDetails
+		err = json.Unmarshal(data, &details)
+		prm.Details = details
+	case report.SecretsManager:
+		var details report.AsmParameterDetails
+		err = json.Unmarshal(data, &details)
+		prm.Details = details
+	}
+
+	return errors.Wrapf(err, "Invalid details for %s", prm.Name)
+}
@@ -0,0 +1,124 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{Env: "dev", Service: "svc", Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/dev/svc/name", Value: "plain", ValueType: "String",
+			Details: report.PmsParameterDetails{Tier: "Standard"}},
+		{Type: report.SecretsManager, Name: "/dev/svc/db", Value: `{"pwd":"s3cr3t"}`, ValueType: "SecureString",
+			Details: report.AsmParameterDetails{StringKey: "pwd"}},
+	}}
+}
+
+func TestWriteReadPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testSnapshot().Write(&buf, "my passphrase"))
+	assert.False(t, strings.Contains(buf.String(), "s3cr3t"))
+	assert.True(t, strings.Contains(buf.String(), "plain"))
+
+	snap, err := Read(bytes.NewReader(buf.Bytes()), "my passphrase")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatVersion, snap.Version)
+	assert.Equal(t, AlgorithmPassphrase, snap.Encryption.Algorithm)
+	assert.Equal(t, `{"pwd":"s3cr3t"}`, snap.Parameters[1].Value)
+	assert.Equal(t, "pwd", snap.Parameters[1].Details.(report.AsmParameterDetails).StringKey)
+	assert.Equal(t, "Standard", string(snap.Parameters[0].Details.(report.PmsParameterDetails).Tier))
+}
+
+func TestWriteReadGeneratedKey(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, testSnapshot().Write(&buf, key))
+
+	snap, err := Read(bytes.NewReader(buf.Bytes()), key)
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmKey, snap.Encryption.Algorithm)
+	assert.Equal(t, `{"pwd":"s3cr3t"}`, snap.Parameters[1].Value)
+
+	other, _ := GenerateKey()
+	_, err = Read(bytes.NewReader(buf.Bytes()), other)
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func TestReadWrongPassphraseFails(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testSnapshot().Write(&buf, "right"))
+
+	_, err := Read(bytes.NewReader(buf.Bytes()), "wrong")
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func TestWriteSecureWithoutKeyFails(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, testSnapshot().Write(&buf, ""))
+}
+
+func TestWriteWithoutSecureNeedsNoKey(t *testing.T) {
+	snap := testSnapshot()
+	snap.Parameters = snap.Parameters[:1]
+
+	var buf bytes.Buffer
+	assert.NoError(t, snap.Write(&buf, ""))
+
+	read, err := Read(bytes.NewReader(buf.Bytes()), "")
+	assert.NoError(t, err)
+	assert.Nil(t, read.Encryption)
+	assert.Equal(t, "plain", read.Parameters[0].Value)
+}
+
+func TestWriteSealsEachSecureValueWithUniqueNonce(t *testing.T) {
+	snap := testSnapshot()
+	snap.Parameters = append(snap.Parameters, snap.Parameters[1], snap.Parameters[1])
+	snap.Parameters[2].Name = "/dev/svc/db2"
+	snap.Parameters[3].Name = "/dev/svc/db3"
+
+	var buf bytes.Buffer
+	assert.NoError(t, snap.Write(&buf, "my passphrase"))
+
+	var written Snapshot
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &written))
+	assert.NotEqual(t, written.Parameters[1].Value, written.Parameters[2].Value)
+	assert.NotEqual(t, written.Parameters[2].Value, written.Parameters[3].Value)
+
+	read, err := Read(bytes.NewReader(buf.Bytes()), "my passphrase")
+	assert.NoError(t, err)
+
+	for _, prm := range read.Parameters[1:] {
+		assert.Equal(t, `{"pwd":"s3cr3t"}`, prm.Value)
+	}
+}
+
+func TestPassphraseKeyIsPbkdf2Sha256(t *testing.T) {
+	// RFC 7914 section 11 PBKDF2-HMAC-SHA256 test vector (first 32 bytes)
+	raw, _ := hex.DecodeString("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc")
+	block, _ := aes.NewCipher(raw)
+	expected, _ := cipher.NewGCM(block)
+
+	enc := &Encryption{Algorithm: AlgorithmPassphrase,
+		Salt: base64.StdEncoding.EncodeToString([]byte("salt")), Iterations: 1}
+
+	aead, err := enc.aead("passwd")
+	assert.NoError(t, err)
+
+	value, err := seal(expected, "s3cr3t", "/dev/svc/db")
+	assert.NoError(t, err)
+
+	plain, err := open(aead, value, "/dev/svc/db")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", plain)
+}
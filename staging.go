@@ -0,0 +1,80 @@
+package ssm
+
+import (
+	"reflect"
+
+	"github.com/mariotoffia/ssm/internal/asm"
+	"github.com/mariotoffia/ssm/parser"
+	"github.com/mariotoffia/ssm/support"
+)
+
+// parseAsm parses the in param struct with only the asm tag parser registered
+func (s *Serializer) parseAsm(v interface{}) (*parser.StructNode, error) {
+	return parser.New(s.service, s.env, s.prefix).
+		RegisterTagParser("asm", asm.NewTagParser()).
+		Parse(reflect.ValueOf(v))
+}
+
+func (s *Serializer) versions(v interface{},
+	filter *support.FieldFilters) (map[string][]support.SecretVersion, error) {
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	node, err := s.parseAsm(v)
+	if err != nil {
+		return nil, err
+	}
+
+	asmRepository, err := s.getAndConfigureAsm()
+	if err != nil {
+		return nil, err
+	}
+
+	return asmRepository.Versions(node, filter)
+}
+
+func (s *Serializer) moveStage(v interface{}, filter *support.FieldFilters,
+	stage string, fromStage string) (map[string]support.FullNameField, error) {
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	node, err := s.parseAsm(v)
+	if err != nil {
+		return nil, err
+	}
+
+	asmRepository, err := s.getAndConfigureAsm()
+	if err != nil {
+		return nil, err
+	}
+
+	if fromStage == "" {
+		return asmRepository.RemoveStage(node, filter, stage), nil
+	}
+
+	return asmRepository.MoveStage(node, filter, stage, fromStage), nil
+}
+
+func (s *Serializer) marshalStaged(v interface{}, filter *support.FieldFilters,
+	stage string) (map[string]support.FullNameField, error) {
+
+	if nil == filter {
+		filter = support.NewFilters()
+	}
+
+	node, err := s.parseAsm(v)
+	if err != nil {
+		return nil, err
+	}
+
+	asmRepository, err := s.getAndConfigureAsm()
+	if err != nil {
+		return nil, err
+	}
+
+	return asmRepository.UpsertStaged(node, filter, stage), nil
+}
@@ -1,6 +1,14 @@
 package support
 
-import "reflect"
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrScheduledForDeletion is set as FullNameField.Error when unmarshal of a secret
+// fails since it has been deleted with a recovery window. It may be restored until
+// the recovery window has passed.
+var ErrScheduledForDeletion = errors.New("secret is scheduled for deletion")
 
 // FullNameField contains the full name both locally
 // using field navigation and the remove name e.g. if
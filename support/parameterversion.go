@@ -0,0 +1,19 @@
+package support
+
+import "time"
+
+// ParameterVersion is a single version of a parameter store parameter.
+type ParameterVersion struct {
+	// Name is the remote name of the parameter
+	Name string
+	// Version is the parameter version, starting at one
+	Version int64
+	// Value is the (decrypted) parameter value
+	Value string
+	// Labels are the labels attached to this version
+	Labels []string
+	// LastModifiedDate is when this version was written
+	LastModifiedDate time.Time
+	// LastModifiedUser is the ARN of the user that wrote this version
+	LastModifiedUser string
+}
@@ -0,0 +1,27 @@
+package support
+
+import "time"
+
+// RemoteState is the live state of a parameter or secret as stored remotely.
+type RemoteState struct {
+	// Name is the remote name
+	Name string
+	// Value is the (decrypted) current value
+	Value string
+	// Version is the current version, e.g. 3 for a parameter or the version id of a secret
+	Version string
+	// LastModified is when the parameter or secret was last changed
+	LastModified time.Time
+	// Tier is the actual tier of a parameter, empty for secrets
+	Tier string
+	// Type is the parameter type e.g. SecureString, empty for secrets
+	Type string
+	// KeyID is the KMS key used to encrypt the value, if any
+	KeyID string
+	// Description is the remote description
+	Description string
+	// Tags is all tags attached to the parameter or secret
+	Tags map[string]string
+	// Policies is the parameter policies or the secret resource policy
+	Policies []string
+}
@@ -0,0 +1,79 @@
+package support
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+const (
+	// StageCurrent is the Secrets Manager staging label for the secret version in use
+	StageCurrent = "AWSCURRENT"
+	// StagePrevious is the Secrets Manager staging label for the last version in use
+	StagePrevious = "AWSPREVIOUS"
+	// StagePending is the Secrets Manager staging label for a version being rotated in
+	StagePending = "AWSPENDING"
+)
+
+// ErrNoAlternate is returned when the alternate version of a RotatingSecret is requested
+// but not present, e.g. no rotation is ongoing.
+var ErrNoAlternate = errors.New("no alternate secret version")
+
+// RotatingSecret holds both the current and an alternate version of a secret. Use it
+// as field type on an asm tagged field to have the secret AWSCURRENT value in Current
+// and the alt tag stage (default AWSPREVIOUS) in Alternate. For example:
+//
+//	Db support.RotatingSecret `asm:"dbctx, alt=AWSPENDING"`
+//
+// This is useful during rotation when the cached current secret is no longer valid
+// and the alternate is, or vice versa.
+type RotatingSecret struct {
+	// Current is the current secret string
+	Current string
+	// Alternate is the secret string of the AlternateStage version. It is empty
+	// if no such version exists.
+	Alternate string
+	// AlternateStage is the staging label of the Alternate e.g. AWSPREVIOUS
+	AlternateStage string
+}
+
+// RotatingSecretType is the reflect.Type of RotatingSecret
+var RotatingSecretType = reflect.TypeOf(RotatingSecret{})
+
+// HasAlternate returns true if a alternate version exists
+func (r *RotatingSecret) HasAlternate() bool { return r.Alternate != "" }
+
+// CurrentAs unmarshals the current JSON secret string into v
+func (r *RotatingSecret) CurrentAs(v interface{}) error {
+	return json.Unmarshal([]byte(r.Current), v)
+}
+
+// AlternateAs unmarshals the alternate JSON secret string into v. If no alternate
+// version is present ErrNoAlternate is returned.
+func (r *RotatingSecret) AlternateAs(v interface{}) error {
+	if !r.HasAlternate() {
+		return ErrNoAlternate
+	}
+
+	return json.Unmarshal([]byte(r.Alternate), v)
+}
+
+// Connect invokes connect with the current secret string and, if that fails,
+// retries with the alternate secret string (if any). It returns the stage that
+// succeeded or the error of the current secret when both fails.
+func (r *RotatingSecret) Connect(connect func(secret string) error) (string, error) {
+	err := connect(r.Current)
+	if err == nil {
+		return StageCurrent, nil
+	}
+
+	if !r.HasAlternate() {
+		return "", err
+	}
+
+	if alterr := connect(r.Alternate); alterr == nil {
+		return r.AlternateStage, nil
+	}
+
+	return "", err
+}
@@ -0,0 +1,65 @@
+package support
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingSecretConnectUsesCurrentFirst(t *testing.T) {
+	secret := RotatingSecret{Current: "cur", Alternate: "prev", AlternateStage: StagePrevious}
+	tried := []string{}
+
+	stage, err := secret.Connect(func(s string) error {
+		tried = append(tried, s)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, StageCurrent, stage)
+	assert.Equal(t, []string{"cur"}, tried)
+}
+
+func TestRotatingSecretConnectFallbackOnAlternate(t *testing.T) {
+	secret := RotatingSecret{Current: "cur", Alternate: "pending", AlternateStage: StagePending}
+
+	stage, err := secret.Connect(func(s string) error {
+		if s == "cur" {
+			return errors.New("login failed")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, StagePending, stage)
+}
+
+func TestRotatingSecretConnectWithoutAlternateReturnsCurrentError(t *testing.T) {
+	secret := RotatingSecret{Current: "cur"}
+	calls := 0
+
+	_, err := secret.Connect(func(s string) error {
+		calls++
+		return errors.New("login failed")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRotatingSecretAsDecodesJSON(t *testing.T) {
+	secret := RotatingSecret{Current: `{"username":"a"}`, Alternate: `{"username":"b"}`,
+		AlternateStage: StagePrevious}
+
+	var cur, alt struct {
+		Username string `json:"username"`
+	}
+
+	assert.NoError(t, secret.CurrentAs(&cur))
+	assert.NoError(t, secret.AlternateAs(&alt))
+	assert.Equal(t, "a", cur.Username)
+	assert.Equal(t, "b", alt.Username)
+
+	assert.Equal(t, ErrNoAlternate, (&RotatingSecret{Current: "{}"}).AlternateAs(&alt))
+}
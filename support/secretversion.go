@@ -0,0 +1,18 @@
+package support
+
+import "time"
+
+// SecretVersion is a single version of a secrets manager secret along with the
+// staging labels attached to it.
+type SecretVersion struct {
+	// Name is the remote name of the secret
+	Name string
+	// VersionID is the unique identifier of the version
+	VersionID string
+	// Stages is the staging labels attached to this version e.g. AWSCURRENT
+	Stages []string
+	// CreatedDate is when this version was created
+	CreatedDate time.Time
+	// LastAccessedDate is the date (no time) when this version was last accessed
+	LastAccessedDate time.Time
+}
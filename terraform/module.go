@@ -0,0 +1,118 @@
+// Package terraform renders Terraform HCL out of a report.Report. Parameters are rendered
+// as aws_ssm_parameter and secrets as aws_secretsmanager_secret and
+// aws_secretsmanager_secret_version resources. Secure values are never written as
+// literals, instead those are sensitive variables.
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Module is the rendered variables and resources
+type Module struct {
+	// Variables is the input variables in report order
+	Variables []Variable
+	// Resources is the resources in report order
+	Resources []Resource
+	// Skipped is the report parameters that could not be rendered, keyed by full
+	// name, with the reason as value.
+	Skipped map[string]string
+}
+
+// Variable is a terraform input variable
+type Variable struct {
+	Name        string
+	Description string
+	Sensitive   bool
+}
+
+// Resource is a terraform resource
+type Resource struct {
+	// Type is the resource type, e.g. aws_ssm_parameter
+	Type string
+	// Name is the resource name
+	Name string
+	// Attributes is the attributes in render order
+	Attributes []Attribute
+}
+
+// Attribute is a single resource attribute. The value is a HCL expression.
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Address returns the resource address, e.g. aws_ssm_parameter.prod_svc_name
+func (r *Resource) Address() string {
+	return r.Type + "." + r.Name
+}
+
+// HCL renders the variables followed by the resources
+func (m *Module) HCL() string {
+	var sb strings.Builder
+
+	for _, v := range m.Variables {
+		sb.WriteString(fmt.Sprintf("variable %s {\n", Quote(v.Name)))
+		sb.WriteString(fmt.Sprintf("  description = %s\n", Quote(v.Description)))
+		sb.WriteString("  type        = string\n")
+
+		if v.Sensitive {
+			sb.WriteString("  sensitive   = true\n")
+		}
+
+		sb.WriteString("}\n\n")
+	}
+
+	for _, r := range m.Resources {
+		width := 0
+		for _, a := range r.Attributes {
+			if len(a.Name) > width {
+				width = len(a.Name)
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("resource %s %s {\n", Quote(r.Type), Quote(r.Name)))
+
+		for _, a := range r.Attributes {
+			sb.WriteString(fmt.Sprintf("  %-*s = %s\n", width, a.Name, a.Value))
+		}
+
+		sb.WriteString("}\n\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Quote renders s as a HCL string literal. Template sequences are escaped.
+func Quote(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// Map renders the map as a HCL object with keys sorted
+func Map(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s = %s", Quote(key), Quote(m[key])))
+	}
+
+	return "{ " + strings.Join(pairs, ", ") + " }"
+}
@@ -0,0 +1,184 @@
+package terraform
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/mariotoffia/ssm/report"
+)
+
+const (
+	// TypeParameter is the terraform type of a parameter store parameter
+	TypeParameter = "aws_ssm_parameter"
+	// TypeSecret is the terraform type of a secrets manager secret
+	TypeSecret = "aws_secretsmanager_secret"
+	// TypeSecretVersion is the terraform type of a secret value
+	TypeSecretVersion = "aws_secretsmanager_secret_version"
+	// TypeRandomPassword is the terraform type that generates the strkey value
+	TypeRandomPassword = "random_password"
+	// DefaultPasswordLength is the random_password length when not set in Options
+	DefaultPasswordLength = 32
+)
+
+// Options controls how the module is rendered
+type Options struct {
+	// PasswordLength is the random_password length of strkey secrets. Default is
+	// DefaultPasswordLength.
+	PasswordLength int
+}
+
+// Render renders a module out of the report. Resource names are derived from the full
+// names and are hence stable, e.g. /prod/test-service/db-host is prod_test_service_db_host.
+//
+// Values of SecureString parameters and secrets are read from sensitive variables and are
+// never written as literals. Secrets with a strkey instead merge the report value, without
+// the strkey, with a random_password result. Parameters of a type not known by the renderer
+// are skipped.
+func Render(rpt *report.Report, opts Options) (*Module, error) {
+	if opts.PasswordLength <= 0 {
+		opts.PasswordLength = DefaultPasswordLength
+	}
+
+	m := &Module{Variables: []Variable{}, Resources: []Resource{}, Skipped: map[string]string{}}
+	names := map[string]string{}
+
+	for i := range rpt.Parameters {
+		prm := &rpt.Parameters[i]
+
+		switch prm.Type {
+		case report.ParameterStore:
+			m.addParameter(ResourceName(prm.Name, names), prm)
+		case report.SecretsManager:
+			m.addSecret(ResourceName(prm.Name, names), prm, opts)
+		default:
+			m.Skipped[prm.Name] = fmt.Sprintf("unsupported parameter type %s", prm.Type)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Module) addParameter(name string, prm *report.Parameter) {
+	secure := prm.ValueType == "SecureString"
+
+	tp := prm.ValueType
+	if tp == "" {
+		tp = "String"
+	}
+
+	attrs := []Attribute{
+		{Name: "name", Value: Quote(prm.Name)},
+		{Name: "type", Value: Quote(tp)},
+	}
+
+	if secure || prm.Value == "" {
+		m.Variables = append(m.Variables, Variable{Name: name, Description: prm.Name, Sensitive: secure})
+		attrs = append(attrs, Attribute{Name: "value", Value: "var." + name})
+	} else {
+		attrs = append(attrs, Attribute{Name: "value", Value: Quote(prm.Value)})
+	}
+
+	if prm.Description != "" {
+		attrs = append(attrs, Attribute{Name: "description", Value: Quote(prm.Description)})
+	}
+
+	if details, ok := prm.PmsDetails(); ok {
+		if details.Tier != "" {
+			attrs = append(attrs, Attribute{Name: "tier", Value: Quote(string(details.Tier))})
+		}
+
+		if details.Pattern != "" {
+			attrs = append(attrs, Attribute{Name: "allowed_pattern", Value: Quote(details.Pattern)})
+		}
+	}
+
+	if secure && prm.KeyID != "" {
+		attrs = append(attrs, Attribute{Name: "key_id", Value: Quote(prm.KeyID)})
+	}
+
+	if len(prm.Tags) > 0 {
+		attrs = append(attrs, Attribute{Name: "tags", Value: Map(prm.Tags)})
+	}
+
+	m.Resources = append(m.Resources, Resource{Type: TypeParameter, Name: name, Attributes: attrs})
+}
+
+func (m *Module) addSecret(name string, prm *report.Parameter, opts Options) {
+	attrs := []Attribute{{Name: "name", Value: Quote(prm.Name)}}
+
+	if prm.Description != "" {
+		attrs = append(attrs, Attribute{Name: "description", Value: Quote(prm.Description)})
+	}
+
+	if prm.KeyID != "" {
+		attrs = append(attrs, Attribute{Name: "kms_key_id", Value: Quote(prm.KeyID)})
+	}
+
+	if len(prm.Tags) > 0 {
+		attrs = append(attrs, Attribute{Name: "tags", Value: Map(prm.Tags)})
+	}
+
+	secret := Resource{Type: TypeSecret, Name: name, Attributes: attrs}
+	m.Resources = append(m.Resources, secret)
+
+	var value string
+
+	if details, ok := prm.AsmDetails(); ok && details.StringKey != "" {
+		password := Resource{Type: TypeRandomPassword, Name: name, Attributes: []Attribute{
+			{Name: "length", Value: fmt.Sprintf("%d", opts.PasswordLength)},
+			{Name: "special", Value: "true"},
+		}}
+
+		m.Resources = append(m.Resources, password)
+
+		value = fmt.Sprintf("jsonencode(merge(jsondecode(%s), { %s = %s.result }))",
+			Quote(prm.SecretStringTemplate(details.StringKey)), Quote(details.StringKey), password.Address())
+	} else {
+		m.Variables = append(m.Variables, Variable{Name: name, Description: prm.Name, Sensitive: true})
+		value = "var." + name
+	}
+
+	m.Resources = append(m.Resources, Resource{Type: TypeSecretVersion, Name: name, Attributes: []Attribute{
+		{Name: "secret_id", Value: secret.Address() + ".id"},
+		{Name: "secret_string", Value: value},
+	}})
+}
+
+// ResourceName derives a terraform resource name from the full name, e.g.
+// /prod/test-service/db-host becomes prod_test_service_db_host. If two names renders
+// the same resource name, a hash of the name is appended. The names map holds the
+// full names keyed by rendered resource name.
+func ResourceName(name string, names map[string]string) string {
+	var sb strings.Builder
+
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			sep = sb.Len() > 0
+			continue
+		}
+
+		if sep {
+			sb.WriteRune('_')
+			sep = false
+		}
+
+		sb.WriteRune(r)
+	}
+
+	rn := sb.String()
+	if rn == "" || unicode.IsDigit(rune(rn[0])) {
+		rn = "p_" + rn
+	}
+
+	if existing, ok := names[rn]; ok && existing != name {
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		rn = fmt.Sprintf("%s_%08x", rn, h.Sum32())
+	}
+
+	names[rn] = name
+	return rn
+}
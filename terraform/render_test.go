@@ -0,0 +1,94 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/ssm/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *report.Report {
+	return &report.Report{Parameters: []report.Parameter{
+		{Type: report.ParameterStore, Name: "/prod/test-service/db-host", Value: "localhost",
+			ValueType: "String", Description: "The host", Tags: map[string]string{"owner": "team"},
+			Details: report.PmsParameterDetails{Tier: "Advanced", Pattern: ".*"}},
+		{Type: report.ParameterStore, Name: "/prod/test-service/pwd", Value: "not in output",
+			ValueType: "SecureString", KeyID: "alias/my-key", Details: report.PmsParameterDetails{Tier: "Standard"}},
+		{Type: report.SecretsManager, Name: "/prod/test-service/dbctx", ValueType: "SecureString",
+			Value:   `{"user":"nisse","password":"not in output"}`,
+			Details: report.AsmParameterDetails{StringKey: "password"}},
+		{Type: report.SecretsManager, Name: "/prod/test-service/apikey", ValueType: "SecureString",
+			Value: "not in output", KeyID: "arn:aws:kms:eu-west-1:123:key/abc", Details: report.AsmParameterDetails{}},
+		{Type: "consul", Name: "/prod/test-service/flag"},
+	}}
+}
+
+func TestRenderNeverWritesSecureValues(t *testing.T) {
+	m, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	hcl := m.HCL()
+	assert.NotContains(t, hcl, "not in output")
+	assert.Contains(t, hcl, "value  = var.prod_test_service_pwd")
+	assert.Contains(t, hcl, "secret_string = var.prod_test_service_apikey")
+
+	assert.Equal(t, []Variable{
+		{Name: "prod_test_service_pwd", Description: "/prod/test-service/pwd", Sensitive: true},
+		{Name: "prod_test_service_apikey", Description: "/prod/test-service/apikey", Sensitive: true},
+	}, m.Variables)
+}
+
+func TestRenderParameter(t *testing.T) {
+	m, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, Resource{Type: TypeParameter, Name: "prod_test_service_db_host", Attributes: []Attribute{
+		{Name: "name", Value: `"/prod/test-service/db-host"`},
+		{Name: "type", Value: `"String"`},
+		{Name: "value", Value: `"localhost"`},
+		{Name: "description", Value: `"The host"`},
+		{Name: "tier", Value: `"Advanced"`},
+		{Name: "allowed_pattern", Value: `".*"`},
+		{Name: "tags", Value: `{ "owner" = "team" }`},
+	}}, m.Resources[0])
+
+	assert.Contains(t, m.Resources[1].Attributes, Attribute{Name: "key_id", Value: `"alias/my-key"`})
+}
+
+func TestRenderStringKeySecretUsesRandomPassword(t *testing.T) {
+	m, err := Render(testReport(), Options{PasswordLength: 24})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, TypeSecret, m.Resources[2].Type)
+	assert.Equal(t, Resource{Type: TypeRandomPassword, Name: "prod_test_service_dbctx", Attributes: []Attribute{
+		{Name: "length", Value: "24"},
+		{Name: "special", Value: "true"},
+	}}, m.Resources[3])
+
+	version := m.Resources[4]
+	assert.Equal(t, TypeSecretVersion, version.Type)
+	assert.Equal(t, "aws_secretsmanager_secret.prod_test_service_dbctx.id", version.Attributes[0].Value)
+	assert.Equal(t, `jsonencode(merge(jsondecode("{\"user\":\"nisse\"}"), `+
+		`{ "password" = random_password.prod_test_service_dbctx.result }))`, version.Attributes[1].Value)
+}
+
+func TestRenderSkipsUnknownTypes(t *testing.T) {
+	m, err := Render(testReport(), Options{})
+	assert.Equal(t, nil, err)
+
+	assert.Contains(t, m.Skipped, "/prod/test-service/flag")
+	assert.Equal(t, 7, len(m.Resources))
+}
+
+func TestQuoteEscapesTemplates(t *testing.T) {
+	assert.Equal(t, `"a\"b $${x} %%{y}\n"`, Quote("a\"b ${x} %{y}\n"))
+}
+
+func TestResourceNameIsStable(t *testing.T) {
+	names := map[string]string{}
+
+	assert.Equal(t, "prod_svc_db_host", ResourceName("/prod/svc/db-host", names))
+	assert.Equal(t, "prod_svc_db_host", ResourceName("/prod/svc/db-host", names))
+	assert.NotEqual(t, "prod_svc_db_host", ResourceName("/prod/svc/db_host", names))
+	assert.Equal(t, "p_1st", ResourceName("1st", names))
+}
@@ -20,16 +20,27 @@ func (s *Serializer) getAndConfigurePms() (*pms.Serializer, error) {
 }
 
 func (s *Serializer) getAndConfigureAsm() (*asm.Serializer, error) {
+	if s.asm != nil {
+		return s.asm, nil
+	}
+
+	var asmRepository *asm.Serializer
+
 	if s.hasconfig {
-		return asm.NewFromConfig(s.config, s.service), nil
+		asmRepository = asm.NewFromConfig(s.config, s.service)
+	} else {
+		var err error
+		if asmRepository, err = asm.New(s.service); err != nil {
+			return nil, err
+		}
 	}
 
-	asmRepository, err := asm.New(s.service)
-	if err != nil {
+	if err := asmRepository.SetRecoveryWindow(s.recoveryWindow); err != nil {
 		return nil, err
 	}
 
-	return asmRepository, nil
+	s.asm = asmRepository.SetConcurrency(s.concurrency)
+	return s.asm, nil
 }
 
 func find(slice []Usage, val Usage) (int, bool) {